@@ -0,0 +1,79 @@
+package asyncqu
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+type stageScopeKey struct{}
+
+// stageScope describes stage that is running with the context.
+type stageScope struct {
+	name    StageName
+	runID   string
+	attempt int
+	causes  []StageName
+	results *resultsStore
+	store   *Blackboard
+	logger  *slog.Logger
+}
+
+func withStageScope(ctx context.Context, scope *stageScope) context.Context {
+	return context.WithValue(ctx, stageScopeKey{}, scope)
+}
+
+func stageScopeFromContext(ctx context.Context) (*stageScope, bool) {
+	scope, ok := ctx.Value(stageScopeKey{}).(*stageScope)
+	return scope, ok
+}
+
+func (s *stageScope) isCause(stageName StageName) bool {
+	for _, c := range s.causes {
+		if c == stageName {
+			return true
+		}
+	}
+	return false
+}
+
+// StageNameFromContext returns name of stage that is running with the context, empty name outside of stage.
+func StageNameFromContext(ctx context.Context) StageName {
+	if scope, ok := stageScopeFromContext(ctx); ok {
+		return scope.name
+	}
+	return ""
+}
+
+// RunIDFromContext returns ID that is unique for each Run call, empty string outside of stage.
+func RunIDFromContext(ctx context.Context) string {
+	if scope, ok := stageScopeFromContext(ctx); ok {
+		return scope.runID
+	}
+	return ""
+}
+
+// AttemptFromContext returns number of current stage attempt starting from 1, zero outside of stage.
+func AttemptFromContext(ctx context.Context) int {
+	if scope, ok := stageScopeFromContext(ctx); ok {
+		return scope.attempt
+	}
+	return 0
+}
+
+// CausesFromContext returns causes of stage that is running with the context.
+func CausesFromContext(ctx context.Context) []StageName {
+	if scope, ok := stageScopeFromContext(ctx); ok {
+		return append([]StageName(nil), scope.causes...)
+	}
+	return nil
+}
+
+func newRunID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // crypto/rand never fails on supported platforms
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,106 @@
+package asyncqu
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStageNameFromContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("outside of stage", func(t *testing.T) {
+		ctx := context.TODO()
+		assert.Equal(t, StageName(""), StageNameFromContext(ctx))
+		assert.Equal(t, "", RunIDFromContext(ctx))
+		assert.Equal(t, 0, AttemptFromContext(ctx))
+		assert.Nil(t, CausesFromContext(ctx))
+	})
+
+	t.Run("inside of stages", func(t *testing.T) {
+		var fakeErr = errors.New("fake error")
+
+		const (
+			stage11 = StageName("stage-1-1")
+			stage12 = StageName("stage-1-2")
+			stage2  = StageName("stage-2")
+		)
+
+		type seen struct {
+			name     StageName
+			runID    string
+			attempts []int
+			causes   []StageName
+		}
+		var (
+			mx    sync.Mutex
+			stats = map[StageName]*seen{}
+		)
+		record := func(ctx context.Context) {
+			mx.Lock()
+			defer mx.Unlock()
+
+			name := StageNameFromContext(ctx)
+			s, ok := stats[name]
+			if !ok {
+				s = &seen{name: name, runID: RunIDFromContext(ctx), causes: CausesFromContext(ctx)}
+				stats[name] = s
+			}
+			s.attempts = append(s.attempts, AttemptFromContext(ctx))
+		}
+
+		executor := New()
+		executor.Append(stage11, func(ctx context.Context) error {
+			record(ctx)
+			return nil
+		}, Start)
+		executor.Append(stage12, func(ctx context.Context) error {
+			record(ctx)
+			if AttemptFromContext(ctx) < 3 {
+				return fakeErr
+			}
+			return nil
+		}, Start)
+		executor.SetStageRetry(stage12, RetryPolicy{MaxAttempts: 3, Backoff: ConstantBackoff(time.Millisecond, 0)})
+		executor.Append(stage2, func(ctx context.Context) error {
+			record(ctx)
+			return nil
+		}, stage11, stage12)
+		executor.SetEnd(stage2)
+		executor.SetFinal(func(ctx context.Context) error {
+			record(ctx)
+			return nil
+		})
+
+		assert.NoError(t, executor.Run(context.TODO()))
+
+		runID := executor.Report().RunID
+		assert.Len(t, runID, 32)
+
+		for _, name := range []StageName{stage11, stage12, stage2, Final} {
+			if assert.Contains(t, stats, name) {
+				assert.Equal(t, name, stats[name].name)
+				assert.Equal(t, runID, stats[name].runID)
+			}
+		}
+		assert.Equal(t, []int{1}, stats[stage11].attempts)
+		assert.Equal(t, []int{1, 2, 3}, stats[stage12].attempts)
+		assert.Equal(t, []StageName{Start}, stats[stage11].causes)
+		assert.Equal(t, []StageName{stage11, stage12}, stats[stage2].causes)
+	})
+
+	t.Run("run id is unique", func(t *testing.T) {
+		ids := map[string]struct{}{}
+		for i := 0; i < 10; i++ {
+			executor := New()
+			executor.SetEnd(Start)
+			assert.NoError(t, executor.Run(context.TODO()))
+			ids[executor.Report().RunID] = struct{}{}
+		}
+		assert.Len(t, ids, 10)
+	})
+}
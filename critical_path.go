@@ -0,0 +1,129 @@
+package asyncqu
+
+import "time"
+
+// CriticalPath is a chain of stages that determined total time of the run.
+type CriticalPath struct {
+	Stages   []StageName                 // from the first stage to the last one before END
+	Duration time.Duration               // sum of durations of stages on the path
+	Slack    map[StageName]time.Duration // how long stage could be delayed without delaying END
+}
+
+// IsCritical reports whether stage is on critical path.
+func (p *CriticalPath) IsCritical(stageName StageName) bool {
+	for _, s := range p.Stages {
+		if s == stageName {
+			return true
+		}
+	}
+	return false
+}
+
+// CriticalPath computes critical path and slack of each stage from durations of the last run.
+// Skipped stages have zero duration.
+func (e *executorImpl) CriticalPath() (*CriticalPath, error) {
+	last := e.lastExecution()
+	if last == nil {
+		return nil, ErrNotRunYet
+	}
+
+	return last.CriticalPath()
+}
+
+// criticalPath computes critical path of the graph from report of its execution.
+func (g *Graph) criticalPath(report *RunReport) (*CriticalPath, error) {
+	if report == nil {
+		return nil, ErrNotRunYet
+	}
+
+	durations := map[StageName]time.Duration{}
+	for _, s := range report.Stages {
+		durations[s.Name] = s.Duration
+	}
+
+	var (
+		earliestStart  = map[StageName]time.Duration{}
+		earliestFinish = map[StageName]time.Duration{Start: 0}
+		order          []StageName // topological order
+		visit          func(stageName StageName) time.Duration
+	)
+
+	visit = func(stageName StageName) time.Duration {
+		if finish, exists := earliestFinish[stageName]; exists {
+			return finish
+		}
+
+		item, exists := g.stagesMap[stageName]
+		if !exists {
+			return 0
+		}
+
+		var start time.Duration
+		for _, c := range item.Causes {
+			if finish := visit(c); finish > start {
+				start = finish
+			}
+		}
+
+		earliestStart[stageName] = start
+		earliestFinish[stageName] = start + durations[stageName]
+		order = append(order, stageName)
+
+		return earliestFinish[stageName]
+	}
+
+	for _, item := range stagesWithEnd(g.stagesMap, g.stagesOrder) {
+		visit(item.Name)
+	}
+
+	total := earliestFinish[End]
+
+	latestFinish := map[StageName]time.Duration{}
+	for _, stageName := range order {
+		latestFinish[stageName] = total
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		stageName := order[i]
+		latestStart := latestFinish[stageName] - durations[stageName]
+		for _, c := range g.stagesMap[stageName].Causes {
+			if finish, exists := latestFinish[c]; exists && latestStart < finish {
+				latestFinish[c] = latestStart
+			}
+		}
+	}
+
+	path := &CriticalPath{
+		Duration: total,
+		Slack:    make(map[StageName]time.Duration, len(order)),
+	}
+	for _, stageName := range order {
+		if stageName != End {
+			path.Slack[stageName] = latestFinish[stageName] - earliestFinish[stageName]
+		}
+	}
+
+	// walk back from END through causes that finished the latest
+	for current := End; ; {
+		var (
+			latest      StageName
+			latestFound bool
+		)
+		for _, c := range g.stagesMap[current].Causes {
+			if _, exists := earliestFinish[c]; !exists {
+				continue
+			}
+			if !latestFound || earliestFinish[c] > earliestFinish[latest] {
+				latest, latestFound = c, true
+			}
+		}
+
+		if !latestFound || latest == Start {
+			break
+		}
+
+		path.Stages = append([]StageName{latest}, path.Stages...)
+		current = latest
+	}
+
+	return path, nil
+}
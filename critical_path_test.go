@@ -0,0 +1,78 @@
+package asyncqu
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_executorImpl_CriticalPath(t *testing.T) {
+	t.Parallel()
+
+	const (
+		stage1     = StageName("stage-1")
+		stageLong  = StageName("stage-2-long")
+		stageShort = StageName("stage-2-short")
+		stage3     = StageName("stage-3")
+	)
+	// start --> stage-1 --> stage-2-long  --> stage-3 --> end
+	//                  \--> stage-2-short -------------/
+
+	fnSleep := func(d time.Duration) StageFn {
+		return func(ctx context.Context) error {
+			time.Sleep(d)
+			return nil
+		}
+	}
+
+	newExecutor := func() Executor {
+		executor := New()
+		executor.Append(stage1, fnSleep(10*time.Millisecond), Start)
+		executor.Append(stageLong, fnSleep(100*time.Millisecond), stage1)
+		executor.Append(stageShort, fnSleep(10*time.Millisecond), stage1)
+		executor.Append(stage3, fnSleep(10*time.Millisecond), stageLong)
+		executor.SetEnd(stage3, stageShort)
+		return executor
+	}
+
+	t.Run("negative - not run yet", func(t *testing.T) {
+		_, err := newExecutor().CriticalPath()
+		assert.ErrorIs(t, err, ErrNotRunYet)
+
+		_, err = newExecutor().Export(DOT, ExportOptions{WithCriticalPath: true})
+		assert.ErrorIs(t, err, ErrNotRunYet)
+	})
+
+	t.Run("positive", func(t *testing.T) {
+		executor := newExecutor()
+		assert.NoError(t, executor.Run(context.TODO()))
+
+		path, err := executor.CriticalPath()
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, []StageName{stage1, stageLong, stage3}, path.Stages)
+		assert.GreaterOrEqual(t, path.Duration, 120*time.Millisecond)
+		assert.Equal(t, time.Duration(0), path.Slack[stage1])
+		assert.Equal(t, time.Duration(0), path.Slack[stageLong])
+		assert.Equal(t, time.Duration(0), path.Slack[stage3])
+		assert.GreaterOrEqual(t, path.Slack[stageShort], 80*time.Millisecond)
+		assert.True(t, path.IsCritical(stageLong))
+		assert.False(t, path.IsCritical(stageShort))
+
+		out, err := executor.Export(DOT, ExportOptions{WithCriticalPath: true})
+		assert.NoError(t, err)
+		assert.Contains(t, out, `"stage-1" -> "stage-2-long" [color=red, penwidth=2];`)
+		assert.Contains(t, out, `"stage-1" -> "stage-2-short";`)
+		assert.Contains(t, out, `"stage-2-long" [shape=box, label="stage-2-long\nslack 0s", color=red, penwidth=2];`)
+
+		out, err = executor.Export(Mermaid, ExportOptions{WithCriticalPath: true})
+		assert.NoError(t, err)
+		assert.Contains(t, out, "    s1[\"stage-2-long<br/>slack 0s\"]\n")
+		assert.Contains(t, out, "    class s0,s1,s3 critical\n")
+		assert.Contains(t, out, "    linkStyle 0,1,3,4 stroke:red,stroke-width:3px\n")
+	})
+}
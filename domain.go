@@ -1,18 +1,58 @@
 package asyncqu
 
-import "context"
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+)
 
 type Executor interface {
 	SetOnChanges(cb OnChangedCb)
+	SetOnRunChanges(cb OnRunChangedCb)
+	SetFailurePolicy(policy FailurePolicy)
+	SetTracer(tracer Tracer)
+	SetName(name string)
+	SetMetrics(metrics Metrics)
+	SetLogger(logger *slog.Logger)
+	SetLogLevel(state State, level slog.Level)
+	SetForwardRefs(allowed bool)
+	SetRepanic(enabled bool)
+	SetTimeout(timeout time.Duration)
+	SetStageTimeout(stageName StageName, timeout time.Duration)
+	SetGracePeriod(gracePeriod time.Duration)
+	SetStageRetry(stageName StageName, policy RetryPolicy)
+	SetMaxParallel(limit int)
+	SetPool(name string, capacity int)
+	SetStageCost(stageName StageName, cost Resources)
+	Pools() []PoolStatus
 	Append(stageName StageName, fn StageFn, clauses ...StageName)
+	TryAppend(stageName StageName, fn StageFn, clauses ...StageName) error
+	Validate() error
 	SetFinal(fn StageFn)
+	AddFinal(fn FinalFn)
 	SetEnd(stageNames ...StageName)
+	Compile() (*Graph, error)
 	Run(ctx context.Context) error
+	AsyncRun(ctx context.Context) error
+	Wait() error
+	Done() <-chan struct{}
+	Reset() error
+	Status() []StageStatus
 	Errs() []error
+	Report() *RunReport
+	Store() *Blackboard
+	Export(format ExportFormat, opts ExportOptions) (string, error)
+	WriteTrace(w io.Writer) error
+	CriticalPath() (*CriticalPath, error)
 }
 
 type OnChangedCb func(stageName StageName, state State, err error)
 
+// OnRunChangedCb works as OnChangedCb and receives ID of the run that stage belongs to.
+type OnRunChangedCb func(runID string, stageName StageName, state State, err error)
+
 type StageFn func(ctx context.Context) error
 
-const ContextKeyStageName = StageName("stage-name")
+// FinalFn is called after all stages are finished, report describes stages of the run.
+type FinalFn func(ctx context.Context, report *RunReport) error
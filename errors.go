@@ -1,9 +1,146 @@
 package asyncqu
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	ErrStageShouldNotWaitForItself = errors.New("stage should not wait for itself")
 	ErrStageWaitForUnknown         = errors.New("stage wait for unknown")
+	ErrStageDuplicate              = errors.New("stage already exists")
+	ErrStageUnreachable            = errors.New("stage is unreachable from start")
+	ErrStageDeadEnd                = errors.New("stage does not lead to end")
+	ErrStageCycle                  = errors.New("dependency cycle")
+	ErrStagesFailed                = errors.New("stages failed")
+	ErrFinalFailed                 = errors.New("final callback failed")
+	ErrExportFormatUnknown         = errors.New("export format is unknown")
+	ErrNotRunYet                   = errors.New("executor has not run yet")
+	ErrRunInProgress               = errors.New("run is already in progress")
 	ErrEndStageIsNotSpecified      = errors.New("end stage is not specifier")
+	ErrStageUnknown                = errors.New("stage is unknown")
+	ErrStageTimeout                = errors.New("timed out")
+	ErrStageAbandoned              = errors.New("abandoned after grace period")
+	ErrPoolUnknown                 = errors.New("pool is unknown")
+	ErrPoolCapacityExceeded        = errors.New("pool capacity exceeded")
+	ErrResultOutsideOfStage        = errors.New("result is accessed outside of stage")
+	ErrResultOfNonCause            = errors.New("stage is not a cause")
+	ErrResultMissing               = errors.New("stage has no result")
+	ErrResultTypeMismatch          = errors.New("result type mismatch")
 )
+
+// ValidationError describes problem of the stage in graph.
+type ValidationError struct {
+	Stage StageName
+	Cause StageName // related cause if any
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Cause != "" {
+		return fmt.Sprintf("stage '%s': %s '%s'", e.Stage, e.Err.Error(), e.Cause)
+	}
+	return fmt.Sprintf("stage '%s': %s", e.Stage, e.Err.Error())
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// StageError is an error returned by stage function with stage name and number of attempt.
+type StageError struct {
+	Stage   StageName
+	Attempt int
+	Err     error
+}
+
+func (e *StageError) Error() string {
+	if e.Attempt > 1 {
+		return fmt.Sprintf("stage '%s' attempt %d: %s", e.Stage, e.Attempt, e.Err.Error())
+	}
+	return fmt.Sprintf("stage '%s': %s", e.Stage, e.Err.Error())
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError is an error of stage function that panicked, it keeps stack trace of the panic.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// RunError is returned by Run if any stage or final callback failed or context was done.
+// It matches ErrStagesFailed, ErrFinalFailed, context error and errors of each failed stage and final callback
+// with errors.Is and errors.As.
+type RunError struct {
+	Stages      []*StageError // failed stages ordered by completion time, interrupted stages are not included
+	Interrupted int           // count of stages interrupted by failure of another stage
+	Skipped     int           // count of skipped stages
+	Done        int           // count of stages finished without error
+	Total       int           // count of stages including END
+	Cause       error         // context error if run was interrupted
+	Final       []error       // errors of final callbacks in order they were called
+}
+
+func (e *RunError) Error() string {
+	parts := make([]string, 0, 2)
+
+	if e.Cause != nil {
+		parts = append(parts, fmt.Sprintf("run interrupted after %d of %d stages done: %s",
+			e.Done, e.Total, e.Cause.Error()))
+	}
+
+	if len(e.Stages) > 0 {
+		details := make([]string, 0, len(e.Stages))
+		for _, stageErr := range e.Stages {
+			details = append(details, stageErr.Error())
+		}
+		counts := fmt.Sprintf("%d failed", len(e.Stages))
+		if e.Interrupted > 0 {
+			counts += fmt.Sprintf(", %d interrupted", e.Interrupted)
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s, %d skipped: %s",
+			ErrStagesFailed.Error(), counts, e.Skipped, strings.Join(details, "; ")))
+	}
+
+	if len(e.Final) > 0 {
+		details := make([]string, 0, len(e.Final))
+		for _, finalErr := range e.Final {
+			details = append(details, finalErr.Error())
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", ErrFinalFailed.Error(), strings.Join(details, "; ")))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+func (e *RunError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Stages)+len(e.Final)+1)
+	if e.Cause != nil {
+		errs = append(errs, e.Cause)
+	}
+	for _, stageErr := range e.Stages {
+		errs = append(errs, stageErr)
+	}
+	errs = append(errs, e.Final...)
+	return errs
+}
+
+func (e *RunError) Is(target error) bool {
+	return (target == ErrStagesFailed && len(e.Stages) > 0) || (target == ErrFinalFailed && len(e.Final) > 0)
+}
@@ -0,0 +1,88 @@
+package asyncqu
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunError(t *testing.T) {
+	t.Parallel()
+
+	var (
+		errNoRows  = errors.New("no rows")
+		errTimeout = errors.New("i/o timeout")
+	)
+
+	const (
+		stageSlow   = StageName("stage-slow")
+		stageMiddle = StageName("stage-middle")
+		stageFast   = StageName("stage-fast")
+	)
+
+	fnFailAfter := func(delay time.Duration, err error) StageFn {
+		return func(ctx context.Context) error {
+			time.Sleep(delay)
+			return err
+		}
+	}
+
+	executor := New()
+	executor.SetFailurePolicy(ContinueIndependent)
+	executor.Append(stageSlow, fnFailAfter(60*time.Millisecond, errTimeout), Start)
+	executor.Append(stageMiddle, fnFailAfter(30*time.Millisecond, errNoRows), Start)
+	executor.Append(stageFast, fnFailAfter(0, errNoRows), Start)
+	executor.SetEnd(stageSlow, stageMiddle, stageFast)
+
+	execErr := executor.Run(context.TODO())
+	assert.ErrorIs(t, execErr, ErrStagesFailed)
+	assert.ErrorIs(t, execErr, errNoRows)
+	assert.ErrorIs(t, execErr, errTimeout)
+	assert.NotErrorIs(t, execErr, context.Canceled)
+
+	var stageErr *StageError
+	if assert.ErrorAs(t, execErr, &stageErr) {
+		assert.Equal(t, stageFast, stageErr.Stage) // the first one by completion time
+	}
+
+	var runErr *RunError
+	if assert.ErrorAs(t, execErr, &runErr) {
+		stages := make([]StageName, 0, len(runErr.Stages))
+		for _, s := range runErr.Stages {
+			stages = append(stages, s.Stage)
+		}
+		assert.Equal(t, []StageName{stageFast, stageMiddle, stageSlow}, stages)
+		assert.Equal(t, 1, runErr.Skipped)
+	}
+
+	errs := executor.Errs()
+	if assert.Len(t, errs, 3) {
+		assert.EqualError(t, errs[0], "stage 'stage-fast': no rows")
+		assert.EqualError(t, errs[1], "stage 'stage-middle': no rows")
+		assert.EqualError(t, errs[2], "stage 'stage-slow': i/o timeout")
+	}
+}
+
+func TestStageError(t *testing.T) {
+	t.Parallel()
+
+	var fakeErr = errors.New("fake error")
+
+	assert.EqualError(t, &StageError{Stage: "stage-1", Attempt: 1, Err: fakeErr}, "stage 'stage-1': fake error")
+	assert.EqualError(t, &StageError{Stage: "stage-1", Attempt: 3, Err: fakeErr}, "stage 'stage-1' attempt 3: fake error")
+	assert.ErrorIs(t, &StageError{Stage: "stage-1", Err: fakeErr}, fakeErr)
+}
+
+func TestPanicError(t *testing.T) {
+	t.Parallel()
+
+	var fakeErr = errors.New("fake error")
+
+	assert.EqualError(t, &PanicError{Value: "boom"}, "panic: boom")
+	assert.EqualError(t, &PanicError{Value: fakeErr}, "panic: fake error")
+	assert.ErrorIs(t, &PanicError{Value: fakeErr}, fakeErr)
+	assert.Nil(t, (&PanicError{Value: 42}).Unwrap())
+}
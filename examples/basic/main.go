@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"time"
 
@@ -21,11 +22,10 @@ func main() {
 		stage4Additional1 = asyncqu.StageName("stage-4-additional-1")
 	)
 
-	executor.SetOnChanges(func(stageName asyncqu.StageName, state asyncqu.State, err error) {
-		fmt.Printf("step %s in status %s with %v error\n", stageName, state, err)
-	})
+	executor.SetLogger(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})))
 
 	executor.Append(stage1LoadData, func(ctx context.Context) error {
+		asyncqu.Logger(ctx).Info("loading data")
 		time.Sleep(time.Second)
 		return nil
 	}, asyncqu.Start)
@@ -55,14 +55,30 @@ func main() {
 
 	executor.SetEnd(stage3Aggregate1, stage3Aggregate2, stage4Additional1)
 
-	if runErr := executor.Run(context.Background()); runErr != nil {
+	if runErr := executor.AsyncRun(context.Background()); runErr != nil {
 		fmt.Printf("ERROR: %s\n", runErr.Error())
 		os.Exit(1)
 	}
 
-	if execErrs := executor.Errs(); len(execErrs) > 0 {
-		for _, err := range execErrs {
-			fmt.Printf("ERROR: %s\n", err.Error())
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+WaitLoop:
+	for {
+		select {
+		case <-executor.Done():
+			break WaitLoop
+		case <-ticker.C:
+			for _, status := range executor.Status() {
+				if status.State == asyncqu.Running {
+					fmt.Printf("%s is running for %s\n", status.Name, status.Elapsed.Round(time.Millisecond))
+				}
+			}
 		}
 	}
+
+	if runErr := executor.Wait(); runErr != nil {
+		fmt.Printf("ERROR: %s\n", runErr.Error())
+		os.Exit(1)
+	}
 }
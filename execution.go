@@ -0,0 +1,718 @@
+package asyncqu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Execution is a single run of compiled graph with its own state of stages, store and report.
+type Execution struct {
+	sync.RWMutex
+
+	graph      *Graph
+	stagesMap  map[StageName]*StageMeta
+	stages     []*StageMeta // in order stages were appended, END stage is the last one
+	causesDone map[StageName]struct{}
+	runID      string
+	startedAt  time.Time
+	runSpan    Span
+	timeline   *timeline
+	results    *resultsStore
+	store      *Blackboard
+	report     *RunReport
+	done       chan struct{}
+	err        error
+}
+
+func newExecution(g *Graph) *Execution {
+	x := &Execution{
+		graph:      g,
+		stagesMap:  make(map[StageName]*StageMeta, len(g.stagesMap)),
+		causesDone: map[StageName]struct{}{Start: {}},
+		runID:      newRunID(),
+		startedAt:  time.Now(),
+		runSpan:    noopSpan{},
+		results:    newResultsStore(),
+		store:      NewBlackboard(),
+		done:       make(chan struct{}),
+	}
+	x.timeline = newTimeline(x.startedAt)
+
+	for _, def := range stagesWithEnd(g.stagesMap, g.stagesOrder) {
+		item := *def
+		x.stagesMap[item.Name] = &item
+		x.stages = append(x.stages, &item)
+	}
+
+	return x
+}
+
+// RunID returns the same ID that RunIDFromContext returns in stages of the execution.
+func (x *Execution) RunID() string {
+	return x.runID
+}
+
+// Wait blocks until execution is finished and returns its result.
+// If repanic is enabled and stage panicked, Wait panics with *PanicError in the caller goroutine.
+func (x *Execution) Wait() error {
+	<-x.done
+
+	x.RLock()
+	runErr := x.err
+	x.RUnlock()
+
+	var panicErr *PanicError
+	if x.graph.repanic && errors.As(runErr, &panicErr) {
+		panic(panicErr)
+	}
+
+	return runErr
+}
+
+// Done returns channel that is closed when execution is finished.
+func (x *Execution) Done() <-chan struct{} {
+	return x.done
+}
+
+// Report returns report of the execution, it is nil until execution is finished.
+func (x *Execution) Report() *RunReport {
+	x.RLock()
+	defer x.RUnlock()
+
+	return x.report
+}
+
+// Store returns blackboard of the execution.
+func (x *Execution) Store() *Blackboard {
+	return x.store
+}
+
+// Status returns snapshot of every stage in order they were appended, END stage is the last one.
+func (x *Execution) Status() []StageStatus {
+	x.RLock()
+	defer x.RUnlock()
+
+	return stageStatuses(x.stages, time.Now())
+}
+
+// Errs returns *StageError of each failed stage ordered by completion time.
+func (x *Execution) Errs() []error {
+	x.RLock()
+	defer x.RUnlock()
+
+	return stageErrs(x.stages)
+}
+
+// Pools returns snapshot of resource pools sorted by name, pools are shared by executions of the graph.
+func (x *Execution) Pools() []PoolStatus {
+	return x.graph.limits.snapshot()
+}
+
+// WriteTrace writes timeline of the execution in Chrome trace event format.
+func (x *Execution) WriteTrace(w io.Writer) error {
+	x.RLock()
+	trace := x.timeline.traceEvents()
+	x.RUnlock()
+
+	return writeTrace(w, trace)
+}
+
+// CriticalPath computes critical path and slack of each stage from durations of the finished execution.
+func (x *Execution) CriticalPath() (*CriticalPath, error) {
+	x.RLock()
+	defer x.RUnlock()
+
+	return x.graph.criticalPath(x.report)
+}
+
+// finished reports whether execution is finished without waiting for it.
+func (x *Execution) finished() bool {
+	select {
+	case <-x.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// run executes stages, scheduler works in the calling goroutine.
+func (x *Execution) run(ctx context.Context) error {
+	g := x.graph
+	stages := x.stages
+
+	for _, item := range stages {
+		x.logTransition(item, Runnable, nil)
+		x.notify(item, Runnable, nil)
+	}
+
+	ctx, runSpan := g.tracer.Start(ctx, SpanNameRun, Attr{Key: AttrStagesCount, Value: len(stages)})
+	x.runSpan = runSpan
+
+	runCtx, runCancel := context.WithCancel(ctx)
+	defer runCancel()
+
+	var (
+		eventsCh   = make(chan stageEvent)
+		running    = 0             // stages of the execution, slots are counted by graph limits
+		releasedCh <-chan struct{} // closed when any execution of graph frees slot that ready stage waits for
+		stoppedBy  StageName       // failed stage that stopped scheduling
+		ctxErr     error           // context error if run was stopped by it
+	)
+
+	// Scheduler goroutine is the only owner of stages state,
+	// stage goroutines report attempts and results to it through eventsCh.
+	schedule := true
+ExecLoop:
+	for {
+		if schedule {
+			if ctx.Err() != nil && !x.isAllFinished() {
+				ctxErr = ctx.Err()
+				break ExecLoop // do not start stages after context is done
+			}
+
+			if g.failurePolicy != ContinueIndependent {
+				if failedStage, failed := x.firstFailedStage(); failed {
+					stoppedBy = failedStage
+					break ExecLoop
+				}
+			}
+
+			x.skipUnreachable()
+
+			if x.isAllFinished() {
+				break ExecLoop
+			}
+
+			releasedCh = nil
+			for _, item := range stages {
+				if item.State == Runnable && x.isCausesDone(item.Causes...) {
+					x.markReady(item)
+
+					acquired, waitCh := g.limits.acquire(item.Cost)
+					if !acquired {
+						releasedCh = waitCh
+						continue // try stages that need another pools
+					}
+
+					running++
+
+					x.changeState(item, Running, nil, time.Now())
+
+					go x.runStage(ctx, runCtx, runCancel, item, eventsCh)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			ctxErr = ctx.Err()
+			break ExecLoop
+		case ev := <-eventsCh:
+			schedule = x.applyEvent(ev)
+			if schedule {
+				running--
+			}
+		case <-releasedCh:
+			schedule = true
+		}
+	}
+
+	// mark all skipped stages as Skipped
+	for _, item := range stages {
+		if item.State != Runnable {
+			continue
+		}
+
+		switch {
+		case stoppedBy != "":
+			x.markSkipped(item, stoppedBy, fmt.Sprintf("scheduling stopped after '%s' failed", stoppedBy))
+		case ctxErr != nil:
+			x.markSkipped(item, "", fmt.Sprintf("run interrupted: %s", ctxErr.Error()))
+		default:
+			x.markSkipped(item, "", "")
+		}
+	}
+
+	// stages that are still running report to scheduler until they finish,
+	// after context is done executor waits for them no longer than grace period
+	var (
+		ctxDone = ctx.Done()
+		graceCh <-chan time.Time
+	)
+WaitLoop:
+	for running > 0 {
+		select {
+		case ev := <-eventsCh:
+			if x.applyEvent(ev) {
+				running--
+			}
+		case <-ctxDone:
+			ctxDone = nil
+			ctxErr = ctx.Err()
+			if g.gracePeriod > 0 {
+				graceTimer := time.NewTimer(g.gracePeriod)
+				defer graceTimer.Stop()
+				graceCh = graceTimer.C
+			}
+		case <-graceCh:
+			x.abandonRunning(ctx)
+
+			go func(running int) {
+				// abandoned stages report when they return, nobody applies it anymore
+				for running > 0 {
+					if ev := <-eventsCh; isFinished(ev.state) {
+						running--
+					}
+				}
+			}(running)
+
+			break WaitLoop
+		}
+	}
+
+	report := x.buildReport(x.startedAt, time.Now())
+	report.Err = report.summary(ctxErr)
+
+	if report.FinalErrs = x.runFinals(ctx, report); len(report.FinalErrs) > 0 {
+		report.Err = report.summary(ctxErr)
+	}
+
+	finishedAt := time.Now()
+	report.FinishedAt = finishedAt
+	report.Duration = finishedAt.Sub(x.startedAt)
+
+	x.Lock()
+	x.report = report
+	x.timeline.finish(finishedAt)
+	x.Unlock()
+
+	g.metrics.RunFinished(g.name, report.Duration, report.Err)
+
+	if g.logger != nil {
+		if report.Err != nil {
+			g.logger.Error("run failed",
+				slog.String("run_id", x.runID), slog.Duration("duration", report.Duration), slog.Any("error", report.Err))
+		} else {
+			g.logger.Info("run done", slog.String("run_id", x.runID), slog.Duration("duration", report.Duration))
+		}
+	}
+
+	if report.Err != nil {
+		runSpan.RecordError(report.Err)
+	}
+	runSpan.End()
+
+	return report.Err
+}
+
+// runFinals calls final callbacks in LIFO order and returns their errors in order they were called.
+// Panic of callback is converted to *PanicError.
+func (x *Execution) runFinals(ctx context.Context, report *RunReport) []error {
+	finals := x.graph.finals
+	if len(finals) == 0 {
+		return nil
+	}
+
+	finalCtx, finalSpan := x.graph.tracer.Start(ctx, SpanNameFinal)
+	defer finalSpan.End()
+
+	execFnCtx := withStageScope(finalCtx, &stageScope{
+		name:    Final,
+		runID:   x.runID,
+		attempt: 1,
+		results: x.results,
+		store:   x.store,
+		logger:  x.stageLogger(Final),
+	})
+
+	var errs []error
+	for i := len(finals) - 1; i >= 0; i-- {
+		fn := finals[i]
+		finalErr := callStage(execFnCtx, func(ctx context.Context) error {
+			return fn(ctx, report)
+		})
+		if finalErr != nil {
+			finalSpan.RecordError(finalErr)
+			errs = append(errs, finalErr)
+		}
+	}
+
+	return errs
+}
+
+// changeState moves stage to the state that was reached at the time,
+// records the transition in timeline and notifies OnChangedCb.
+// It is called by scheduler only, so OnChangedCb is never called concurrently during run.
+func (x *Execution) changeState(item *StageMeta, state State, err error, at time.Time) {
+	g := x.graph
+
+	firstStart := state == Running && item.StartedAt.IsZero() // retries move stage to Running again
+
+	x.Lock()
+	item.State = state
+	if firstStart {
+		item.StartedAt = at
+	}
+	if isFinished(state) {
+		item.FinishedAt = at
+	}
+	x.timeline.record(item.Name, state, err, at)
+	x.Unlock()
+
+	x.logTransition(item, state, err)
+
+	switch state {
+	case Running:
+		if !firstStart {
+			break
+		}
+		g.metrics.StageStarted(g.name, item.Name, item.StartedAt.Sub(item.ReadyAt))
+	case Done, Interrupted, Cancelled:
+		if err != nil {
+			g.metrics.StageFailed(g.name, item.Name, item.FinishedAt.Sub(item.StartedAt))
+		} else {
+			g.metrics.StageSucceeded(g.name, item.Name, item.FinishedAt.Sub(item.StartedAt))
+		}
+	case Skipped:
+		g.metrics.StageSkipped(g.name, item.Name)
+	}
+
+	if state == Skipped {
+		x.runSpan.AddEvent(EventStageSkipped,
+			Attr{Key: AttrStageName, Value: string(item.Name)},
+			Attr{Key: AttrSkipReason, Value: item.SkipReason})
+	}
+
+	x.notify(item, state, err)
+}
+
+// notify calls change callbacks of the graph.
+func (x *Execution) notify(item *StageMeta, state State, err error) {
+	x.graph.onChangesCb(item.Name, state, err)
+	x.graph.onRunChangesCb(x.runID, item.Name, state, err)
+}
+
+// stageEvent is sent by stage goroutine to scheduler, that applies it to the stage.
+type stageEvent struct {
+	item        *StageMeta
+	state       State // Retrying or Running for attempts, Done, Interrupted or Cancelled when stage is finished
+	attempt     int
+	err         error
+	attemptErrs []error
+	leaked      bool
+	at          time.Time // when stage goroutine reached the state, scheduler may apply it later
+}
+
+// runStage calls stage with retries and reports the result to scheduler.
+// FailFast policy cancels runCtx on the first error, stages that return context.Canceled after that are interrupted.
+// Stages that fail after ctx is done are cancelled.
+func (x *Execution) runStage(
+	ctx, runCtx context.Context, runCancel context.CancelFunc, item *StageMeta, eventsCh chan<- stageEvent,
+) {
+	stageCtx, stageSpan := x.graph.tracer.Start(runCtx, string(item.Name),
+		Attr{Key: AttrStageName, Value: string(item.Name)}, causesAttr(item.Causes))
+
+	ev := x.execStageWithRetries(stageCtx, item, stageSpan, eventsCh)
+	ev.at = time.Now()
+
+	ev.state = Done
+	switch {
+	case ev.err == nil:
+	case ctx.Err() != nil:
+		ev.state = Cancelled
+	case x.graph.failurePolicy == FailFast:
+		if runCtx.Err() != nil && errors.Is(ev.err, context.Canceled) {
+			// stage was cancelled by another failed stage
+			ev.state = Interrupted
+		} else {
+			runCancel()
+		}
+	}
+
+	stageSpan.SetAttributes(
+		Attr{Key: AttrAttempt, Value: ev.attempt},
+		Attr{Key: AttrStageState, Value: string(ev.state)},
+	)
+	if ev.err != nil {
+		stageSpan.RecordError(ev.err)
+	}
+	stageSpan.End()
+
+	eventsCh <- ev
+}
+
+// applyEvent updates stage with event of its goroutine, it reports whether stage is finished.
+// It is called by scheduler only.
+func (x *Execution) applyEvent(ev stageEvent) bool {
+	item := ev.item
+	finished := isFinished(ev.state)
+
+	x.Lock()
+	item.Attempts = ev.attempt
+	if finished {
+		item.Err = ev.err
+		item.AttemptErrs = ev.attemptErrs
+		item.Leaked = ev.leaked
+		x.causesDone[item.Name] = struct{}{}
+	}
+	x.Unlock()
+
+	if finished {
+		x.graph.limits.release(item.Cost)
+	}
+
+	x.changeState(item, ev.state, ev.err, ev.at)
+
+	return finished
+}
+
+// abandonRunning marks stages that ignore cancellation as Cancelled and leaked.
+func (x *Execution) abandonRunning(ctx context.Context) {
+	for _, item := range x.stages {
+		if item.State != Running && item.State != Retrying {
+			continue
+		}
+
+		abandonErr := fmt.Errorf("stage '%s' %w %s: %w", item.Name, ErrStageAbandoned, x.graph.gracePeriod, ctx.Err())
+
+		x.Lock()
+		item.Err = abandonErr
+		item.Leaked = true
+		x.Unlock()
+
+		x.graph.limits.release(item.Cost)
+
+		x.changeState(item, Cancelled, abandonErr, time.Now())
+	}
+}
+
+// markReady remembers when all causes of stage got done.
+func (x *Execution) markReady(item *StageMeta) {
+	if !item.ReadyAt.IsZero() {
+		return
+	}
+
+	x.Lock()
+	defer x.Unlock()
+
+	item.ReadyAt = time.Now()
+}
+
+// markSkipped moves runnable stage to Skipped state with the reason.
+func (x *Execution) markSkipped(item *StageMeta, skippedBy StageName, reason string) {
+	x.Lock()
+	item.SkippedBy = skippedBy
+	item.SkipReason = reason
+	x.Unlock()
+
+	x.changeState(item, Skipped, nil, time.Now())
+}
+
+// execStageWithRetries calls stage until it succeeds or retry policy allows to try again.
+// Backoff delay is interrupted if context is done. Retries are reported to scheduler through eventsCh.
+func (x *Execution) execStageWithRetries(
+	ctx context.Context, item *StageMeta, span Span, eventsCh chan<- stageEvent,
+) stageEvent {
+	ev := stageEvent{item: item}
+
+	for attempt := 1; ; attempt++ {
+		ev.attempt = attempt
+
+		leaked, resErr := x.execStage(ctx, item, attempt)
+		ev.leaked = ev.leaked || leaked
+		if resErr == nil {
+			ev.err = nil
+			return ev
+		}
+
+		ev.err = resErr
+		ev.attemptErrs = append(ev.attemptErrs, resErr)
+
+		if attempt >= item.Retry.MaxAttempts || ctx.Err() != nil {
+			return ev
+		}
+		if item.Retry.Retryable != nil && !item.Retry.Retryable(resErr) {
+			return ev
+		}
+
+		eventsCh <- stageEvent{
+			item:    item,
+			state:   Retrying,
+			attempt: attempt,
+			err:     &StageError{Stage: item.Name, Attempt: attempt, Err: resErr},
+			at:      time.Now(),
+		}
+		span.AddEvent(EventStageRetry, Attr{Key: AttrAttempt, Value: attempt})
+		span.RecordError(resErr)
+
+		if item.Retry.Backoff != nil {
+			timer := time.NewTimer(item.Retry.Backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ev
+			case <-timer.C:
+			}
+		}
+
+		eventsCh <- stageEvent{item: item, state: Running, attempt: attempt + 1, at: time.Now()}
+	}
+}
+
+// execStage calls stage function, bounded with stage timeout if it is specified.
+// If stage function ignores expired context, executor does not wait for it and marks stage as leaked.
+// It reports whether stage function leaked.
+func (x *Execution) execStage(ctx context.Context, item *StageMeta, attempt int) (bool, error) {
+	if item.Fn == nil {
+		return false, nil
+	}
+
+	execFnCtx := withStageScope(ctx, &stageScope{
+		name:    item.Name,
+		runID:   x.runID,
+		attempt: attempt,
+		causes:  item.Causes,
+		results: x.results,
+		store:   x.store,
+		logger:  x.stageLogger(item.Name),
+	})
+
+	timeout := x.graph.timeout
+	if item.Timeout > 0 {
+		timeout = item.Timeout
+	}
+	if timeout <= 0 {
+		return false, callStage(execFnCtx, item.Fn)
+	}
+
+	execFnCtx, execFnCancel := context.WithTimeout(execFnCtx, timeout)
+	defer execFnCancel()
+
+	resCh := make(chan error, 1)
+	go func() {
+		resCh <- callStage(execFnCtx, item.Fn)
+	}()
+
+	isTimedOut := func() bool {
+		return ctx.Err() == nil && errors.Is(execFnCtx.Err(), context.DeadlineExceeded)
+	}
+	result := func(resErr error) error {
+		if resErr != nil && isTimedOut() {
+			return fmt.Errorf("%w after %s: %w", ErrStageTimeout, timeout, context.DeadlineExceeded)
+		}
+		return resErr
+	}
+
+	select {
+	case resErr := <-resCh:
+		return false, result(resErr)
+	case <-execFnCtx.Done():
+		if !isTimedOut() {
+			// parent context is done, wait for stage as without timeout
+			return false, <-resCh
+		}
+
+		select {
+		case resErr := <-resCh:
+			// stage returned at the deadline, its result wins
+			return false, result(resErr)
+		default:
+			return true, result(execFnCtx.Err())
+		}
+	}
+}
+
+// callStage calls stage function and converts its panic to *PanicError.
+func callStage(ctx context.Context, fn StageFn) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn(ctx)
+}
+
+// Helpers below are called by scheduler only. It is the only writer of stages state,
+// so it reads the state without lock and does not wait for concurrent readers.
+
+func (x *Execution) isCausesDone(causes ...StageName) bool {
+	for _, s := range causes {
+		if _, exists := x.causesDone[s]; !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// failedOrSkippedCause returns the first cause that failed or was skipped.
+func (x *Execution) failedOrSkippedCause(causes ...StageName) (StageName, bool) {
+	for _, s := range causes {
+		item, exists := x.stagesMap[s]
+		if !exists {
+			continue
+		}
+
+		if (isFinished(item.State) && item.Err != nil) || item.State == Skipped {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// skipUnreachable marks as Skipped every runnable stage which waits for failed or skipped stage.
+// Repeats until nothing changes, so skipping is propagated through the whole downstream.
+func (x *Execution) skipUnreachable() {
+	for changed := true; changed; {
+		changed = false
+
+		for _, item := range x.stages {
+			if item.State != Runnable {
+				continue
+			}
+
+			cause, found := x.failedOrSkippedCause(item.Causes...)
+			if !found {
+				continue
+			}
+
+			if x.stagesMap[cause].State == Skipped {
+				x.markSkipped(item, cause, fmt.Sprintf("cause '%s' skipped", cause))
+			} else {
+				x.markSkipped(item, cause, fmt.Sprintf("cause '%s' failed", cause))
+			}
+			changed = true
+		}
+	}
+}
+
+// firstFailedStage returns failed stage that was finished first.
+func (x *Execution) firstFailedStage() (StageName, bool) {
+	var first *StageMeta
+	for _, item := range x.stages {
+		if isFinished(item.State) && item.Err != nil {
+			if first == nil || item.FinishedAt.Before(first.FinishedAt) {
+				first = item
+			}
+		}
+	}
+	if first == nil {
+		return "", false
+	}
+	return first.Name, true
+}
+
+func (x *Execution) isAllFinished() bool {
+	for _, item := range x.stages {
+		if !isFinished(item.State) {
+			return false
+		}
+	}
+	return true
+}
@@ -2,8 +2,11 @@ package asyncqu
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"log/slog"
+	"sort"
 	"sync"
+	"time"
 )
 
 func New() Executor {
@@ -17,22 +20,39 @@ func New() Executor {
 			},
 		},
 
-		startedFlag: false,
-		causesDone:  map[StageName]struct{}{},
-		onChangesCb: func(name StageName, state State, err error) {},
-		finalCb:     nil,
+		onChangesCb:    func(name StageName, state State, err error) {},
+		onRunChangesCb: func(runID string, name StageName, state State, err error) {},
+		failurePolicy:  StopScheduling,
+		pools:          map[string]*PoolStatus{},
+		tracer:         noopTracer{},
+		metrics:        noopMetrics{},
+		logLevels:      defaultLogLevels(),
 	}
 }
 
 type executorImpl struct {
 	sync.RWMutex
 
-	stagesMap map[StageName]*StageMeta
-
-	startedFlag bool
-	causesDone  map[StageName]struct{}
-	onChangesCb OnChangedCb
-	finalCb     StageFn
+	stagesMap   map[StageName]*StageMeta
+	stagesOrder []StageName
+
+	onChangesCb    OnChangedCb
+	onRunChangesCb OnRunChangedCb
+	finals         []FinalFn
+	failurePolicy  FailurePolicy
+	timeout        time.Duration
+	maxParallel    int
+	appendErrs     []error
+	tracer         Tracer
+	metrics        Metrics
+	logger         *slog.Logger
+	logLevels      map[State]slog.Level
+	name           string
+	forwardRefs    bool
+	repanic        bool
+	gracePeriod    time.Duration
+	pools          map[string]*PoolStatus // capacities, usage is tracked by compiled graph
+	last           *Execution
 }
 
 func (e *executorImpl) SetOnChanges(cb OnChangedCb) {
@@ -42,27 +62,207 @@ func (e *executorImpl) SetOnChanges(cb OnChangedCb) {
 	e.onChangesCb = cb
 }
 
-func (e *executorImpl) Append(stageName StageName, fn StageFn, causes ...StageName) {
+// SetOnRunChanges sets callback that is notified as OnChangedCb together with ID of the run.
+func (e *executorImpl) SetOnRunChanges(cb OnRunChangedCb) {
 	e.Lock()
 	defer e.Unlock()
 
-	delete(e.stagesMap, End)
+	e.onRunChangesCb = cb
+}
+
+func (e *executorImpl) SetFailurePolicy(policy FailurePolicy) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.failurePolicy = policy
+}
+
+// SetTracer sets tracer that creates span for each run with child spans for stages.
+// Context of stage function carries the span of stage.
+func (e *executorImpl) SetTracer(tracer Tracer) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.tracer = tracer
+}
+
+// SetName sets executor name that labels metrics.
+func (e *executorImpl) SetName(name string) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.name = name
+}
+
+// SetMetrics sets receiver of stage and run measurements.
+func (e *executorImpl) SetMetrics(metrics Metrics) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.metrics = metrics
+}
+
+// SetLogger sets logger of state transitions, nil disables logging.
+// Stage functions get the logger with stage name attribute by Logger(ctx).
+func (e *executorImpl) SetLogger(logger *slog.Logger) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.logger = logger
+}
+
+// SetLogLevel sets level of transitions to state.
+func (e *executorImpl) SetLogLevel(state State, level slog.Level) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.logLevels[state] = level
+}
+
+// SetRepanic makes Run and Wait panic with *PanicError of the first panicked stage after the run is finished,
+// so final callback, report and other cleanup are done before crash.
+func (e *executorImpl) SetRepanic(enabled bool) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.repanic = enabled
+}
+
+// SetGracePeriod limits how long Run waits for running stages after its context is done.
+// Stages that are still running after that are marked as Cancelled and leaked. Zero means wait until they return.
+func (e *executorImpl) SetGracePeriod(gracePeriod time.Duration) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.gracePeriod = gracePeriod
+}
 
-	if _, exists := e.stagesMap[stageName]; exists {
-		panic(fmt.Errorf("stage with name '%s' already exists", stageName))
+// SetTimeout sets default timeout for every stage, zero means no timeout.
+func (e *executorImpl) SetTimeout(timeout time.Duration) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.timeout = timeout
+}
+
+// SetStageTimeout sets timeout for stage, it overrides executor-wide timeout.
+func (e *executorImpl) SetStageTimeout(stageName StageName, timeout time.Duration) {
+	e.Lock()
+	defer e.Unlock()
+
+	item, exists := e.stagesMap[stageName]
+	if !exists {
+		panic(ErrStageUnknown)
 	}
 
-	for _, c := range causes {
-		if c == stageName {
-			panic(ErrStageShouldNotWaitForItself)
-		}
-		if c != Start {
-			if _, exists := e.stagesMap[c]; !exists {
-				panic(ErrStageWaitForUnknown)
-			}
+	item.Timeout = timeout
+}
+
+// SetStageRetry sets retry policy for stage, each attempt is bounded with stage timeout separately.
+func (e *executorImpl) SetStageRetry(stageName StageName, policy RetryPolicy) {
+	e.Lock()
+	defer e.Unlock()
+
+	item, exists := e.stagesMap[stageName]
+	if !exists {
+		panic(ErrStageUnknown)
+	}
+
+	item.Retry = policy
+}
+
+// SetMaxParallel limits count of simultaneously running stages, zero means no limit.
+// Ready stages wait in Runnable state for free slot in order they were appended.
+func (e *executorImpl) SetMaxParallel(limit int) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.maxParallel = limit
+}
+
+// SetPool creates or resizes named resource pool.
+func (e *executorImpl) SetPool(name string, capacity int) {
+	e.Lock()
+	defer e.Unlock()
+
+	if p, exists := e.pools[name]; exists {
+		p.Capacity = capacity
+		return
+	}
+
+	e.pools[name] = &PoolStatus{Name: name, Capacity: capacity}
+}
+
+// SetStageCost declares units of pools that stage holds while running.
+// Stage starts only when every pool it needs has enough free units.
+func (e *executorImpl) SetStageCost(stageName StageName, cost Resources) {
+	e.Lock()
+	defer e.Unlock()
+
+	item, exists := e.stagesMap[stageName]
+	if !exists {
+		panic(ErrStageUnknown)
+	}
+
+	item.Cost = cost
+}
+
+// Pools returns snapshot of resource pools of the last run sorted by name.
+func (e *executorImpl) Pools() []PoolStatus {
+	if last := e.lastExecution(); last != nil {
+		return last.Pools()
+	}
+
+	e.RLock()
+	defer e.RUnlock()
+
+	return poolsSnapshot(e.pools)
+}
+
+func poolsSnapshot(poolsMap map[string]*PoolStatus) []PoolStatus {
+	pools := make([]PoolStatus, 0, len(poolsMap))
+	for _, p := range poolsMap {
+		pools = append(pools, *p)
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].Name < pools[j].Name
+	})
+
+	return pools
+}
+
+func (e *executorImpl) Append(stageName StageName, fn StageFn, causes ...StageName) {
+	if appendErr := e.TryAppend(stageName, fn, causes...); appendErr != nil {
+		var validationErr *ValidationError
+		if errors.As(appendErr, &validationErr) && !errors.Is(appendErr, ErrStageDuplicate) {
+			panic(validationErr.Err)
 		}
+		panic(appendErr)
+	}
+}
+
+// SetForwardRefs allows to append stages that wait for stages appended later.
+// Causes are resolved by Validate and Run then.
+func (e *executorImpl) SetForwardRefs(allowed bool) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.forwardRefs = allowed
+}
+
+// TryAppend works as Append but returns error instead of panic.
+// Rejected stages are remembered and reported by Validate too.
+func (e *executorImpl) TryAppend(stageName StageName, fn StageFn, causes ...StageName) error {
+	e.Lock()
+	defer e.Unlock()
+
+	if appendErr := e.checkAppend(stageName, causes...); appendErr != nil {
+		e.appendErrs = append(e.appendErrs, appendErr)
+		return appendErr
 	}
 
+	delete(e.stagesMap, End)
+
 	item := &StageMeta{
 		Name:   stageName,
 		Fn:     fn,
@@ -70,7 +270,30 @@ func (e *executorImpl) Append(stageName StageName, fn StageFn, causes ...StageNa
 		Causes: causes,
 	}
 	e.stagesMap[stageName] = item
-	e.onChangesCb(item.Name, item.State, nil)
+	if stageName != End {
+		e.stagesOrder = append(e.stagesOrder, stageName) // END stage is always the last one
+	}
+
+	return nil
+}
+
+func (e *executorImpl) checkAppend(stageName StageName, causes ...StageName) error {
+	if _, exists := e.stagesMap[stageName]; exists && stageName != End {
+		return &ValidationError{Stage: stageName, Err: ErrStageDuplicate}
+	}
+
+	for _, c := range causes {
+		if c == stageName {
+			return &ValidationError{Stage: stageName, Err: ErrStageShouldNotWaitForItself}
+		}
+		if c != Start && !e.forwardRefs {
+			if _, exists := e.stagesMap[c]; !exists {
+				return &ValidationError{Stage: stageName, Cause: c, Err: ErrStageWaitForUnknown}
+			}
+		}
+	}
+
+	return nil
 }
 
 func (e *executorImpl) SetEnd(causes ...StageName) {
@@ -85,163 +308,136 @@ func (e *executorImpl) SetEnd(causes ...StageName) {
 	}
 }
 
+// SetFinal replaces all final callbacks with the job, nil removes them.
 func (e *executorImpl) SetFinal(job StageFn) {
 	e.Lock()
 	defer e.Unlock()
 
-	e.finalCb = job
-}
-
-func (e *executorImpl) Run(ctx context.Context) error {
-	if !e.hasEnd() {
-		return ErrEndStageIsNotSpecified
+	e.finals = nil
+	if job != nil {
+		e.finals = append(e.finals, func(ctx context.Context, _ *RunReport) error {
+			return job(ctx)
+		})
 	}
+}
 
+// AddFinal registers final callback that is called after all stages are finished.
+// Callbacks are called in reverse order of registration like deferred calls, each of them is called
+// even if previous one failed.
+func (e *executorImpl) AddFinal(fn FinalFn) {
 	e.Lock()
-	e.startedFlag = true
-	e.causesDone[Start] = struct{}{}
-	e.Unlock()
-
-	var (
-		doneCh     = make(chan StageName)
-		execNextCh = make(chan struct{}, 1)
-	)
-
-	execNextCh <- struct{}{} // initial push running
-
-	go func(ctx context.Context) {
-		// catch signal about finished stages
-		for stageName := range doneCh {
-			e.Lock()
-			e.causesDone[stageName] = struct{}{}
-			e.Unlock()
-
-			execNextCh <- struct{}{}
-		}
-
-		close(execNextCh)
-	}(ctx)
-
-	activeTasksWg := sync.WaitGroup{}
+	defer e.Unlock()
 
-ExecLoop:
-	for {
-		select {
-		case <-ctx.Done():
-			break ExecLoop
-		case <-execNextCh:
-			allDone := true
-			skippedCount := 0
+	e.finals = append(e.finals, fn)
+}
 
-			for _, item := range e.stagesMap {
-				if item.State == Done || item.State == Skipped {
-					continue
-				}
+// Run executes stages and waits until they are finished, it is AsyncRun followed by Wait.
+func (e *executorImpl) Run(ctx context.Context) error {
+	if runErr := e.AsyncRun(ctx); runErr != nil {
+		return runErr
+	}
 
-				allDone = false
+	return e.Wait()
+}
 
-				if item.State == Runnable && e.isAnyCausesFailedOrSkipped(item.Causes...) {
-					item.State = Skipped
-					e.onChangesCb(item.Name, item.State, nil)
-					skippedCount++
-					continue
-				}
+// AsyncRun compiles the graph and starts execution of stages in background.
+// It returns error only if run cannot be started, result of the run is returned by Wait.
+// Each run starts with fresh state, executor reports the last one.
+func (e *executorImpl) AsyncRun(ctx context.Context) error {
+	graph, compileErr := e.Compile()
+	if compileErr != nil {
+		return compileErr
+	}
 
-				if item.State == Runnable && e.isCausesDone(item.Causes...) {
-					item.State = Running
-					e.onChangesCb(item.Name, item.State, nil)
+	e.Lock()
+	defer e.Unlock()
 
-					activeTasksWg.Add(1)
+	if e.last != nil && !e.last.finished() {
+		return ErrRunInProgress
+	}
+	e.last = graph.Start(ctx)
 
-					go func(ctx context.Context, item *StageMeta) {
-						if item.Fn != nil {
-							execFnCtx := context.WithValue(ctx, ContextKeyStageName, item.Name)
+	return nil
+}
 
-							if resErr := item.Fn(execFnCtx); resErr != nil {
-								item.Err = resErr
-							}
-						}
+// Wait blocks until the last run is finished and returns its result.
+// If repanic is enabled and stage panicked, Wait panics with *PanicError in the caller goroutine.
+func (e *executorImpl) Wait() error {
+	last := e.lastExecution()
+	if last == nil {
+		return ErrNotRunYet
+	}
 
-						item.State = Done
-						e.onChangesCb(item.Name, item.State, item.Err)
+	return last.Wait()
+}
 
-						doneCh <- item.Name
+// Done returns channel that is closed when the last started run is finished, nil before the first run.
+func (e *executorImpl) Done() <-chan struct{} {
+	last := e.lastExecution()
+	if last == nil {
+		return nil
+	}
 
-						activeTasksWg.Done()
-					}(ctx, item)
-				}
-			}
+	return last.Done()
+}
 
-			if skippedCount > 0 {
-				break ExecLoop
-			}
+// Reset forgets the last run, so executor looks like it has never run.
+// Definition of the graph is kept.
+func (e *executorImpl) Reset() error {
+	e.Lock()
+	defer e.Unlock()
 
-			if allDone {
-				break ExecLoop
-			}
-		}
+	if e.last != nil && !e.last.finished() {
+		return ErrRunInProgress
 	}
+	e.last = nil
 
-	// mark all skipped stages as Skipped
-	for stageName := range e.stagesMap {
-		if e.stagesMap[stageName].State != Runnable {
-			continue
-		}
+	return nil
+}
 
-		e.stagesMap[stageName].State = Skipped
-		e.onChangesCb(e.stagesMap[stageName].Name, e.stagesMap[stageName].State, nil)
+// Report returns report of the last run, it is nil before the first run.
+func (e *executorImpl) Report() *RunReport {
+	last := e.lastExecution()
+	if last == nil {
+		return nil
 	}
 
-	activeTasksWg.Wait()
-	close(doneCh)
+	return last.Report()
+}
 
-	if e.finalCb != nil {
-		execFnCtx := context.WithValue(ctx, ContextKeyStageName, Final)
-		_ = e.finalCb(execFnCtx)
+// Store returns blackboard of the last run, it is empty before the first run.
+func (e *executorImpl) Store() *Blackboard {
+	last := e.lastExecution()
+	if last == nil {
+		return NewBlackboard()
 	}
 
-	return nil
+	return last.Store()
 }
 
+// Errs returns *StageError of each failed stage of the last run ordered by completion time.
 func (e *executorImpl) Errs() []error {
-	errs := make([]error, 0)
-	for _, item := range e.stagesMap {
-		if item.Err != nil {
-			errs = append(errs, item.Err)
-		}
+	last := e.lastExecution()
+	if last == nil {
+		return []error{}
 	}
 
-	return errs
+	return last.Errs()
 }
 
-func (e *executorImpl) isCausesDone(causes ...StageName) bool {
+func (e *executorImpl) lastExecution() *Execution {
 	e.RLock()
 	defer e.RUnlock()
 
-	for _, s := range causes {
-		if _, exists := e.causesDone[s]; !exists {
-			return false
-		}
-	}
-	return true
+	return e.last
 }
 
-func (e *executorImpl) isAnyCausesFailedOrSkipped(causes ...StageName) bool {
+// checkCosts checks that every stage cost can be satisfied with pools.
+func (e *executorImpl) checkCosts() error {
 	e.RLock()
 	defer e.RUnlock()
 
-	for _, s := range causes {
-		for _, item := range e.stagesMap {
-			if item.Name != s {
-				continue
-			}
-
-			if (item.State == Done && item.Err != nil) || item.State == Skipped {
-				return true
-			}
-		}
-	}
-	return false
+	return errors.Join(e.costProblems()...)
 }
 
 func (e *executorImpl) hasEnd() bool {
@@ -256,3 +452,7 @@ func (e *executorImpl) hasEnd() bool {
 
 	return false
 }
+
+func isFinished(state State) bool {
+	return state == Done || state == Skipped || state == Interrupted || state == Cancelled
+}
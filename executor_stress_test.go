@@ -0,0 +1,235 @@
+package asyncqu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stressGraph appends layers of stages, each stage waits for up to three stages of previous layer.
+// Every stage whose index is divisible by failEach returns error.
+func stressGraph(executor Executor, layers, width, failEach int) (total int, fakeErr error) {
+	fakeErr = errors.New("fake error")
+
+	name := func(layer, i int) StageName {
+		return StageName(fmt.Sprintf("stage-%d-%d", layer, i))
+	}
+
+	var last []StageName
+	for layer := 0; layer < layers; layer++ {
+		for i := 0; i < width; i++ {
+			causes := []StageName{Start}
+			if layer > 0 {
+				causes = []StageName{name(layer-1, i)}
+				if i > 0 {
+					causes = append(causes, name(layer-1, i-1))
+				}
+				if i+1 < width {
+					causes = append(causes, name(layer-1, i+1))
+				}
+			}
+
+			fail := failEach > 0 && total%failEach == failEach-1
+			total++
+
+			executor.Append(name(layer, i), func(ctx context.Context) error {
+				Store(ctx).Set(string(StageNameFromContext(ctx)), AttemptFromContext(ctx))
+				if fail {
+					return fakeErr
+				}
+				return nil
+			}, causes...)
+
+			if layer == layers-1 {
+				last = append(last, name(layer, i))
+			}
+		}
+	}
+	executor.SetEnd(last...)
+
+	return total, fakeErr
+}
+
+func Test_executorImpl_Run_stress(t *testing.T) {
+	t.Parallel()
+
+	layers, width := 50, 60
+	if testing.Short() {
+		layers, width = 30, 40 // keeps the suite fast with race detector
+	}
+
+	t.Run("thousands of stages with concurrent reads", func(t *testing.T) {
+		executor := New()
+		executor.SetMaxParallel(32)
+		executor.SetFailurePolicy(ContinueIndependent)
+		executor.SetPool("db", 8)
+
+		var (
+			transitions sync.Map // StageName -> *int64
+			running     int64
+			maxRunning  int64
+		)
+		executor.SetOnChanges(func(stageName StageName, state State, err error) {
+			counter, _ := transitions.LoadOrStore(stageName, new(int64))
+			atomic.AddInt64(counter.(*int64), 1)
+
+			switch state {
+			case Running:
+				if n := atomic.AddInt64(&running, 1); n > atomic.LoadInt64(&maxRunning) {
+					atomic.StoreInt64(&maxRunning, n)
+				}
+			case Done:
+				atomic.AddInt64(&running, -1)
+			}
+		})
+
+		total, fakeErr := stressGraph(executor, layers, width, 997)
+		executor.SetStageCost("stage-0-0", Resources{"db": 2})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		readersWg := sync.WaitGroup{}
+		for i := 0; i < 4; i++ {
+			readersWg.Add(1)
+			go func() {
+				defer readersWg.Done()
+
+				for n := 0; ctx.Err() == nil; n++ {
+					_ = executor.Errs()
+					_ = executor.Status()
+					_ = executor.Pools()
+					_ = executor.Report()
+					_ = executor.Store().Snapshot()
+					if n%100 == 0 {
+						_ = executor.WriteTrace(io.Discard)
+						_, _ = executor.Export(Mermaid, ExportOptions{WithStates: true})
+					}
+					time.Sleep(time.Millisecond)
+				}
+			}()
+		}
+
+		runErr := executor.Run(context.Background())
+		cancel()
+		readersWg.Wait()
+
+		assert.ErrorIs(t, runErr, fakeErr)
+		assert.LessOrEqual(t, maxRunning, int64(32))
+		assert.Equal(t, int64(0), running)
+
+		report := executor.Report()
+		if !assert.NotNil(t, report) {
+			return
+		}
+		assert.Len(t, report.Stages, total+1) // END stage too
+
+		failed := report.Failed()
+		assert.Len(t, executor.Errs(), len(failed))
+		assert.NotEmpty(t, failed)
+		for _, stage := range report.Stages {
+			assert.True(t, isFinished(stage.State), stage.Name)
+		}
+		for _, stage := range report.Skipped() {
+			assert.NotEmpty(t, stage.SkipReason, stage.Name)
+		}
+
+		var started int
+		for _, stage := range report.Stages {
+			if stage.Name != End && !stage.StartedAt.IsZero() {
+				started++
+			}
+		}
+		assert.Equal(t, started, len(executor.Store().Snapshot())) // every started stage writes its name
+	})
+
+	t.Run("fail fast with retries", func(t *testing.T) {
+		executor := New()
+		executor.SetMaxParallel(16)
+		executor.SetFailurePolicy(FailFast)
+
+		total, fakeErr := stressGraph(executor, 20, 50, 331)
+		for i := 0; i < 50; i++ {
+			executor.SetStageRetry(StageName(fmt.Sprintf("stage-0-%d", i)), RetryPolicy{
+				MaxAttempts: 2,
+				Backoff:     ConstantBackoff(time.Millisecond, 0),
+			})
+		}
+
+		stop := make(chan struct{})
+		readersWg := sync.WaitGroup{}
+		readersWg.Add(1)
+		go func() {
+			defer readersWg.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					_ = executor.Errs()
+					_, _ = executor.CriticalPath()
+					time.Sleep(time.Millisecond)
+				}
+			}
+		}()
+
+		runErr := executor.Run(context.Background())
+		close(stop)
+		readersWg.Wait()
+
+		assert.ErrorIs(t, runErr, fakeErr)
+
+		report := executor.Report()
+		if !assert.NotNil(t, report) {
+			return
+		}
+		assert.Len(t, report.Stages, total+1)
+		assert.NotEmpty(t, report.Skipped())
+		for _, stage := range report.Stages {
+			assert.True(t, isFinished(stage.State), stage.Name)
+		}
+	})
+
+	t.Run("concurrent executions of compiled graph", func(t *testing.T) {
+		executor := New()
+		executor.SetMaxParallel(8)
+		executor.SetFailurePolicy(ContinueIndependent)
+
+		total, fakeErr := stressGraph(executor, layers/2, width/2, 97)
+
+		graph, compileErr := executor.Compile()
+		if !assert.NoError(t, compileErr) {
+			return
+		}
+
+		executions := make([]*Execution, 4)
+		for i := range executions {
+			executions[i] = graph.Start(context.Background())
+		}
+
+		for _, x := range executions {
+			assert.ErrorIs(t, x.Wait(), fakeErr)
+
+			report := x.Report()
+			if !assert.NotNil(t, report) {
+				continue
+			}
+			assert.Len(t, report.Stages, total+1)
+			assert.Len(t, x.Errs(), len(report.Failed()))
+
+			var started int
+			for _, stage := range report.Stages {
+				if stage.Name != End && !stage.StartedAt.IsZero() {
+					started++
+				}
+			}
+			assert.Equal(t, started, len(x.Store().Snapshot()))
+		}
+	})
+}
@@ -3,6 +3,9 @@ package asyncqu
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -55,7 +58,7 @@ func Test_executorImpl_SetOnChanges(t *testing.T) {
 			executor.SetEnd(stage4)
 
 			execErr := executor.Run(context.TODO())
-			assert.NoError(t, execErr)
+			assert.ErrorIs(t, execErr, ErrStagesFailed)
 
 			assert.Equal(t, 6, statesCounter[Runnable]) // six functions registered
 			assert.Equal(t, 2, statesCounter[Running])  // stage-1 and stage-2, another are skipped
@@ -71,9 +74,13 @@ func Test_executorImpl_Append(t *testing.T) {
 	t.Run("negative", func(t *testing.T) {
 		t.Run("panic: duplicates", func(t *testing.T) {
 			defer func() {
-				if r := recover(); r == nil {
+				r := recover()
+				if r == nil {
 					t.Errorf("The code did not panic")
+					t.FailNow()
 				}
+				assert.ErrorIs(t, r.(error), ErrStageDuplicate)
+				assert.EqualError(t, r.(error), "stage 'stage-1': stage already exists")
 			}()
 
 			executor := New()
@@ -109,6 +116,26 @@ func Test_executorImpl_Append(t *testing.T) {
 			executor.Append("stage-2", nil, "stage-1")
 		})
 	})
+
+	t.Run("end stage with function", func(t *testing.T) {
+		var calls int32
+
+		executor := New()
+		executor.Append("stage-1", nil, Start)
+		assert.NoError(t, executor.TryAppend(End, func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		}, "stage-1"))
+
+		assert.NoError(t, executor.Run(context.TODO()))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+		statuses := executor.Status()
+		if assert.Len(t, statuses, 2) {
+			assert.Equal(t, StageName("stage-1"), statuses[0].Name)
+			assert.Equal(t, End, statuses[1].Name)
+		}
+	})
 }
 
 func Test_executor_Run(t *testing.T) {
@@ -160,7 +187,7 @@ func Test_executor_Run(t *testing.T) {
 
 			executor := New()
 			executor.Append(stage1, func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				select {
 				case <-ctx.Done():
@@ -173,7 +200,7 @@ func Test_executor_Run(t *testing.T) {
 				return nil
 			}, Start)
 			executor.Append(stage21, func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				time.Sleep(250 * time.Millisecond)
 				spy.Append(stage)
@@ -181,7 +208,7 @@ func Test_executor_Run(t *testing.T) {
 				return nil
 			}, stage1)
 			executor.Append(stage22, func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				spy.Append(stage)
 				t.Logf("stage %s is done", stage)
@@ -189,7 +216,7 @@ func Test_executor_Run(t *testing.T) {
 			}, stage1)
 			executor.SetEnd(stage21, stage22)
 			executor.SetFinal(func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 				spy.Append(stage)
 				return nil
 			})
@@ -198,7 +225,7 @@ func Test_executor_Run(t *testing.T) {
 			defer runCancel()
 
 			execErr := executor.Run(runCtx)
-			assert.NoError(t, execErr)
+			assert.ErrorIs(t, execErr, context.DeadlineExceeded)
 
 			assert.Equal(t, 1, spy.Len())
 			assert.Equal(t, Final, spy.At(0))
@@ -221,12 +248,12 @@ func Test_executor_Run(t *testing.T) {
 
 			executor := New()
 			executor.SetFinal(func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 				spy.Append(stage)
 				return nil
 			})
 			executor.Append(stage1, func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				time.Sleep(200 * time.Millisecond)
 
@@ -235,7 +262,7 @@ func Test_executor_Run(t *testing.T) {
 				return nil
 			}, Start)
 			executor.Append(stage21, func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				time.Sleep(200 * time.Millisecond)
 
@@ -244,7 +271,7 @@ func Test_executor_Run(t *testing.T) {
 				return nil
 			}, stage1)
 			executor.Append(stage22, func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				time.Sleep(1000 * time.Millisecond)
 
@@ -253,7 +280,7 @@ func Test_executor_Run(t *testing.T) {
 				return nil
 			}, stage1)
 			executor.Append(stage3, func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				time.Sleep(1000 * time.Millisecond)
 
@@ -267,7 +294,7 @@ func Test_executor_Run(t *testing.T) {
 			defer execCancel()
 
 			execErr := executor.Run(execCtx)
-			assert.NoError(t, execErr)
+			assert.ErrorIs(t, execErr, context.DeadlineExceeded)
 
 			assert.Equal(t, 4, spy.Len())
 			assert.Equal(t, stage1, spy.At(0))
@@ -291,14 +318,14 @@ func Test_executor_Run(t *testing.T) {
 			spy := NewStageVisitSpy()
 
 			executor.Append(stage1, func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				spy.Append(stage)
 				t.Logf("stage %s is done", stage)
 				return fakeErr
 			}, Start)
 			executor.Append(stage21, func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				time.Sleep(250 * time.Millisecond)
 				spy.Append(stage)
@@ -306,7 +333,7 @@ func Test_executor_Run(t *testing.T) {
 				return nil
 			}, stage1)
 			executor.Append(stage22, func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				spy.Append(stage)
 				t.Logf("stage %s is done", stage)
@@ -315,7 +342,7 @@ func Test_executor_Run(t *testing.T) {
 			executor.SetEnd(stage21, stage22)
 
 			execErr := executor.Run(context.TODO())
-			assert.NoError(t, execErr)
+			assert.ErrorIs(t, execErr, ErrStagesFailed)
 
 			assert.Len(t, executor.Errs(), 1)
 
@@ -370,7 +397,7 @@ func Test_executor_Run(t *testing.T) {
 				return func(ctx context.Context) error {
 					time.Sleep(sleep)
 
-					stage := ctx.Value(ContextKeyStageName).(StageName)
+					stage := StageNameFromContext(ctx)
 
 					spy.Append(stage)
 					t.Logf("stage %s is done", stage)
@@ -405,7 +432,7 @@ func Test_executor_Run(t *testing.T) {
 
 			executor := New()
 			executor.Append(stage1, func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				select {
 				case <-ctx.Done():
@@ -418,7 +445,7 @@ func Test_executor_Run(t *testing.T) {
 				return nil
 			}, Start)
 			executor.Append(stage21, func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				select {
 				case <-ctx.Done():
@@ -431,7 +458,7 @@ func Test_executor_Run(t *testing.T) {
 				return fakeErr
 			}, stage1)
 			executor.Append(stage22, func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				select {
 				case <-ctx.Done():
@@ -449,7 +476,7 @@ func Test_executor_Run(t *testing.T) {
 			defer runCancel()
 
 			runErr := executor.Run(runCtx)
-			assert.NoError(t, runErr)
+			assert.ErrorIs(t, runErr, ErrStagesFailed)
 
 			assert.Equal(t, 3, spy.Len())
 			assert.Equal(t, stage1, spy.At(0))
@@ -459,6 +486,627 @@ func Test_executor_Run(t *testing.T) {
 	})
 }
 
+func Test_executorImpl_SetFailurePolicy(t *testing.T) {
+	t.Parallel()
+
+	var fakeErr = errors.New("fake error")
+
+	const (
+		stage1  = StageName("stage-1")
+		stage21 = StageName("stage-2-1")
+		stage22 = StageName("stage-2-2")
+		stage31 = StageName("stage-3-1")
+		stage32 = StageName("stage-3-2")
+	)
+	// start --> stage-1 --> stage-2-1 -- ERROR --> stage-3-1 --> end
+	//                  \--> stage-2-2 ----------> stage-3-2 /
+
+	newExecutor := func(spy *stageVisitSpy) Executor {
+		executor := New()
+
+		fnNormal := func(sleep time.Duration) StageFn {
+			return func(ctx context.Context) error {
+				time.Sleep(sleep)
+				spy.Append(StageNameFromContext(ctx))
+				return nil
+			}
+		}
+
+		executor.Append(stage1, fnNormal(0), Start)
+		executor.Append(stage21, func(ctx context.Context) error {
+			spy.Append(StageNameFromContext(ctx))
+			return fakeErr
+		}, stage1)
+		executor.Append(stage22, fnNormal(100*time.Millisecond), stage1)
+		executor.Append(stage31, fnNormal(0), stage21)
+		executor.Append(stage32, fnNormal(0), stage22)
+		executor.SetEnd(stage31, stage32)
+
+		return executor
+	}
+
+	t.Run("stop scheduling by default", func(t *testing.T) {
+		spy := NewStageVisitSpy()
+		executor := newExecutor(spy)
+
+		states := map[StageName]State{}
+		statesMx := sync.Mutex{}
+		executor.SetOnChanges(func(stageName StageName, state State, err error) {
+			statesMx.Lock()
+			states[stageName] = state
+			statesMx.Unlock()
+		})
+
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrStagesFailed)
+
+		assert.Len(t, executor.Errs(), 1)
+		assert.Equal(t, 3, spy.Len()) // stage-1, stage-2-1 and stage-2-2 that was already running
+		assert.Equal(t, Skipped, states[stage31])
+		assert.Equal(t, Skipped, states[stage32])
+	})
+
+	t.Run("continue independent", func(t *testing.T) {
+		spy := NewStageVisitSpy()
+		executor := newExecutor(spy)
+		executor.SetFailurePolicy(ContinueIndependent)
+
+		states := map[StageName]State{}
+		statesMx := sync.Mutex{}
+		executor.SetOnChanges(func(stageName StageName, state State, err error) {
+			statesMx.Lock()
+			states[stageName] = state
+			statesMx.Unlock()
+		})
+
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrStagesFailed)
+
+		assert.Len(t, executor.Errs(), 1)
+		assert.Equal(t, 4, spy.Len())
+		assert.Equal(t, stage32, spy.At(3))
+		assert.Equal(t, Skipped, states[stage31])
+		assert.Equal(t, Done, states[stage32])
+		assert.Equal(t, Skipped, states[End])
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		const (
+			stageFailed = StageName("stage-failed")
+			stageQuick  = StageName("stage-quick")
+			stageLong   = StageName("stage-long")
+		)
+		// start --> stage-failed -- ERROR --> end
+		//      \--> stage-quick -------------/
+		//      \--> stage-long --------------/
+
+		executor := New()
+		executor.SetFailurePolicy(FailFast)
+
+		states := map[StageName]State{}
+		statesMx := sync.Mutex{}
+		executor.SetOnChanges(func(stageName StageName, state State, err error) {
+			statesMx.Lock()
+			states[stageName] = state
+			statesMx.Unlock()
+		})
+
+		executor.Append(stageFailed, func(ctx context.Context) error {
+			time.Sleep(50 * time.Millisecond)
+			return fakeErr
+		}, Start)
+		executor.Append(stageQuick, func(ctx context.Context) error {
+			return nil
+		}, Start)
+		executor.Append(stageLong, func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+				return nil
+			}
+		}, Start)
+		executor.SetEnd(stageFailed, stageQuick, stageLong)
+
+		startedAt := time.Now()
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrStagesFailed)
+		assert.Less(t, time.Since(startedAt), time.Second)
+
+		assert.Len(t, executor.Errs(), 2)
+		assert.EqualError(t, execErr, "stages failed: 1 failed, 1 interrupted, 1 skipped: stage 'stage-failed': fake error")
+		assert.Equal(t, Done, states[stageFailed])
+		assert.Equal(t, Done, states[stageQuick])
+		assert.Equal(t, Interrupted, states[stageLong])
+		assert.Equal(t, Skipped, states[End])
+	})
+
+	t.Run("fail fast reports root cause first", func(t *testing.T) {
+		const (
+			stageFailed  = StageName("stage-failed")
+			siblingCount = 8
+		)
+		// start --> stage-failed -- ERROR --> end
+		//      \--> stage-0..7 --------------/
+
+		for i := 0; i < 50; i++ {
+			executor := New()
+			executor.SetFailurePolicy(FailFast)
+			executor.Append(stageFailed, func(ctx context.Context) error {
+				return fakeErr
+			}, Start)
+			ends := []StageName{stageFailed}
+			for j := 0; j < siblingCount; j++ {
+				sibling := StageName(fmt.Sprintf("stage-%d", j))
+				executor.Append(sibling, func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				}, Start)
+				ends = append(ends, sibling)
+			}
+			executor.SetEnd(ends...)
+
+			execErr := executor.Run(context.TODO())
+
+			var runErr *RunError
+			if !assert.ErrorAs(t, execErr, &runErr) {
+				return
+			}
+			if !assert.Len(t, runErr.Stages, 1) || !assert.Equal(t, stageFailed, runErr.Stages[0].Stage) {
+				return
+			}
+			assert.Equal(t, siblingCount, runErr.Interrupted)
+
+			var stageErr *StageError
+			if assert.ErrorAs(t, executor.Errs()[0], &stageErr) {
+				assert.Equal(t, stageFailed, stageErr.Stage)
+			}
+		}
+	})
+
+	t.Run("fail fast with failures at the same time", func(t *testing.T) {
+		const (
+			stageA = StageName("stage-a")
+			stageB = StageName("stage-b")
+		)
+		// start --> stage-a -- ERROR --> end
+		//      \--> stage-b -- ERROR --/
+
+		var (
+			errA = errors.New("a boom")
+			errB = errors.New("b boom")
+		)
+
+		executor := New()
+		executor.SetFailurePolicy(FailFast)
+
+		barrier := sync.WaitGroup{}
+		barrier.Add(2)
+		fnFailed := func(fnErr error) StageFn {
+			return func(ctx context.Context) error {
+				barrier.Done()
+				barrier.Wait()
+				return fnErr
+			}
+		}
+		executor.Append(stageA, fnFailed(errA), Start)
+		executor.Append(stageB, fnFailed(errB), Start)
+		executor.SetEnd(stageA, stageB)
+
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, errA)
+		assert.ErrorIs(t, execErr, errB)
+
+		report := executor.Report()
+		for _, expected := range []struct {
+			stageName StageName
+			err       error
+		}{{stageA, errA}, {stageB, errB}} {
+			stage, _ := report.Stage(expected.stageName)
+			assert.Equal(t, Done, stage.State, stage.Name)
+			assert.ErrorIs(t, stage.Err, expected.err)
+		}
+	})
+}
+
+func Test_executorImpl_SetTimeout(t *testing.T) {
+	t.Parallel()
+
+	const (
+		stageCooperative = StageName("stage-cooperative")
+		stageStuck       = StageName("stage-stuck")
+		stageFast        = StageName("stage-fast")
+	)
+
+	fnCooperative := func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	}
+	fnStuck := func(ctx context.Context) error {
+		time.Sleep(5 * time.Second)
+		return nil
+	}
+	fnFast := func(ctx context.Context) error {
+		return nil
+	}
+
+	t.Run("executor-wide timeout", func(t *testing.T) {
+		executor := New()
+		executor.SetFailurePolicy(ContinueIndependent)
+		executor.SetTimeout(100 * time.Millisecond)
+		executor.Append(stageCooperative, fnCooperative, Start)
+		executor.Append(stageFast, fnFast, Start)
+		executor.SetEnd(stageCooperative, stageFast)
+
+		startedAt := time.Now()
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrStagesFailed)
+		assert.Less(t, time.Since(startedAt), time.Second)
+
+		errs := executor.Errs()
+		if assert.Len(t, errs, 1) {
+			assert.ErrorIs(t, errs[0], ErrStageTimeout)
+			assert.ErrorIs(t, errs[0], context.DeadlineExceeded)
+			assert.EqualError(t, errs[0], "stage 'stage-cooperative': timed out after 100ms: context deadline exceeded")
+		}
+		assert.ErrorIs(t, execErr, context.DeadlineExceeded)
+	})
+
+	t.Run("stage timeout overrides executor-wide one", func(t *testing.T) {
+		executor := New()
+		executor.SetTimeout(5 * time.Millisecond)
+		executor.Append(stageFast, func(ctx context.Context) error {
+			time.Sleep(50 * time.Millisecond)
+			return ctx.Err()
+		}, Start)
+		executor.SetStageTimeout(stageFast, time.Second)
+		executor.SetEnd(stageFast)
+
+		execErr := executor.Run(context.TODO())
+		assert.NoError(t, execErr)
+		assert.Len(t, executor.Errs(), 0)
+	})
+
+	t.Run("stuck stage does not hang the run", func(t *testing.T) {
+		executor := New()
+		executor.Append(stageStuck, fnStuck, Start)
+		executor.SetStageTimeout(stageStuck, 100*time.Millisecond)
+		executor.SetEnd(stageStuck)
+
+		startedAt := time.Now()
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrStagesFailed)
+		assert.Less(t, time.Since(startedAt), time.Second)
+
+		errs := executor.Errs()
+		assert.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], ErrStageTimeout)
+
+		stuck, _ := executor.Report().Stage(stageStuck)
+		assert.True(t, stuck.Leaked)
+	})
+
+	t.Run("panic: unknown stage", func(t *testing.T) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				t.Errorf("The code did not panic")
+				t.FailNow()
+			}
+			assert.ErrorIs(t, ErrStageUnknown, r.(error))
+		}()
+
+		executor := New()
+		executor.SetStageTimeout("stage-1", time.Second)
+	})
+}
+
+func Test_executorImpl_SetStageRetry(t *testing.T) {
+	t.Parallel()
+
+	var (
+		fakeErr      = errors.New("fake error")
+		permanentErr = errors.New("permanent error")
+	)
+
+	const stage1 = StageName("stage-1")
+
+	t.Run("succeeded after retries", func(t *testing.T) {
+		calls := 0
+
+		executor := New()
+		executor.Append(stage1, func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return fakeErr
+			}
+			return nil
+		}, Start)
+		executor.SetStageRetry(stage1, RetryPolicy{
+			MaxAttempts: 5,
+			Backoff:     ConstantBackoff(10*time.Millisecond, 0),
+		})
+		executor.SetEnd(stage1)
+
+		var retries []int
+		executor.SetOnChanges(func(stageName StageName, state State, err error) {
+			if state != Retrying {
+				return
+			}
+
+			var stageErr *StageError
+			if assert.ErrorAs(t, err, &stageErr) {
+				assert.ErrorIs(t, err, fakeErr)
+				assert.Equal(t, stage1, stageErr.Stage)
+				retries = append(retries, stageErr.Attempt)
+			}
+		})
+
+		execErr := executor.Run(context.TODO())
+		assert.NoError(t, execErr)
+
+		assert.Len(t, executor.Errs(), 0)
+		assert.Equal(t, []int{1, 2}, retries)
+		stage, _ := executor.Report().Stage(stage1)
+		assert.Equal(t, 3, stage.Attempts)
+		assert.Equal(t, []error{fakeErr, fakeErr}, stage.AttemptErrs)
+		assert.Equal(t, []error{fakeErr, fakeErr}, executor.Status()[0].AttemptErrs)
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		executor := New()
+		executor.Append(stage1, func(ctx context.Context) error {
+			return fakeErr
+		}, Start)
+		executor.SetStageRetry(stage1, RetryPolicy{MaxAttempts: 3})
+		executor.SetEnd(stage1)
+
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrStagesFailed)
+
+		assert.Len(t, executor.Errs(), 1)
+		stage, _ := executor.Report().Stage(stage1)
+		assert.Equal(t, 3, stage.Attempts)
+		assert.Equal(t, []error{fakeErr, fakeErr, fakeErr}, stage.AttemptErrs)
+	})
+
+	t.Run("error is not retryable", func(t *testing.T) {
+		executor := New()
+		executor.Append(stage1, func(ctx context.Context) error {
+			return permanentErr
+		}, Start)
+		executor.SetStageRetry(stage1, RetryPolicy{
+			MaxAttempts: 3,
+			Retryable: func(err error) bool {
+				return !errors.Is(err, permanentErr)
+			},
+		})
+		executor.SetEnd(stage1)
+
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrStagesFailed)
+
+		assert.Equal(t, []error{&StageError{Stage: stage1, Attempt: 1, Err: permanentErr}}, executor.Errs())
+		stage, _ := executor.Report().Stage(stage1)
+		assert.Equal(t, 1, stage.Attempts)
+	})
+
+	t.Run("backoff interrupted by context", func(t *testing.T) {
+		executor := New()
+		executor.Append(stage1, func(ctx context.Context) error {
+			return fakeErr
+		}, Start)
+		executor.SetStageRetry(stage1, RetryPolicy{
+			MaxAttempts: 3,
+			Backoff:     ConstantBackoff(time.Minute, 0),
+		})
+		executor.SetEnd(stage1)
+
+		execCtx, execCancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+		defer execCancel()
+
+		startedAt := time.Now()
+		_ = executor.Run(execCtx)
+		assert.Less(t, time.Since(startedAt), time.Second)
+
+		stage, _ := executor.Report().Stage(stage1)
+		assert.Equal(t, 1, stage.Attempts)
+	})
+}
+
+func Test_executorImpl_SetMaxParallel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("limit is never exceeded under heavy fan-out", func(t *testing.T) {
+		const (
+			limit       = 8
+			fanOutCount = 500
+			stageLoader = StageName("stage-loader")
+		)
+
+		var (
+			running    int64
+			maxRunning int64
+			doneCount  int64
+		)
+
+		fnTracked := func(ctx context.Context) error {
+			current := atomic.AddInt64(&running, 1)
+			for {
+				prev := atomic.LoadInt64(&maxRunning)
+				if current <= prev || atomic.CompareAndSwapInt64(&maxRunning, prev, current) {
+					break
+				}
+			}
+
+			time.Sleep(time.Millisecond)
+
+			atomic.AddInt64(&running, -1)
+			atomic.AddInt64(&doneCount, 1)
+			return nil
+		}
+
+		executor := New()
+		executor.SetMaxParallel(limit)
+		executor.Append(stageLoader, fnTracked, Start)
+
+		fanOut := make([]StageName, 0, fanOutCount)
+		for i := 0; i < fanOutCount; i++ {
+			stageName := StageName(fmt.Sprintf("stage-aggregate-%d", i))
+			executor.Append(stageName, fnTracked, stageLoader)
+			fanOut = append(fanOut, stageName)
+		}
+		executor.SetEnd(fanOut...)
+
+		execErr := executor.Run(context.TODO())
+		assert.NoError(t, execErr)
+
+		assert.Len(t, executor.Errs(), 0)
+		assert.Equal(t, int64(fanOutCount+1), atomic.LoadInt64(&doneCount))
+		assert.LessOrEqual(t, atomic.LoadInt64(&maxRunning), int64(limit))
+	})
+
+	t.Run("ready stages start in order they were appended", func(t *testing.T) {
+		const (
+			stage1 = StageName("stage-1")
+			stage2 = StageName("stage-2")
+			stage3 = StageName("stage-3")
+		)
+
+		spy := NewStageVisitSpy()
+		fnSpy := func(ctx context.Context) error {
+			spy.Append(StageNameFromContext(ctx))
+			return nil
+		}
+
+		executor := New()
+		executor.SetMaxParallel(1)
+		executor.Append(stage3, fnSpy, Start)
+		executor.Append(stage1, fnSpy, Start)
+		executor.Append(stage2, fnSpy, Start)
+		executor.SetEnd(stage1, stage2, stage3)
+
+		execErr := executor.Run(context.TODO())
+		assert.NoError(t, execErr)
+
+		assert.Equal(t, 3, spy.Len())
+		assert.Equal(t, stage3, spy.At(0))
+		assert.Equal(t, stage1, spy.At(1))
+		assert.Equal(t, stage2, spy.At(2))
+	})
+}
+
+func Test_executorImpl_SetPool(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stages share pools without starving each other", func(t *testing.T) {
+		const (
+			dbCapacity  = 2
+			loaderCount = 10
+			stageCPU    = StageName("stage-cpu")
+		)
+
+		var (
+			dbUsage    int64
+			maxDBUsage int64
+			dbDone     int64
+			cpuRanWith int64 = -1 // count of finished loaders seen by CPU-bound stage
+		)
+
+		executor := New()
+		executor.SetPool("db", dbCapacity)
+		executor.SetPool("cpu", 1)
+
+		loaders := make([]StageName, 0, loaderCount)
+		for i := 0; i < loaderCount; i++ {
+			stageName := StageName(fmt.Sprintf("stage-loader-%d", i))
+			executor.Append(stageName, func(ctx context.Context) error {
+				current := atomic.AddInt64(&dbUsage, 1)
+				for {
+					prev := atomic.LoadInt64(&maxDBUsage)
+					if current <= prev || atomic.CompareAndSwapInt64(&maxDBUsage, prev, current) {
+						break
+					}
+				}
+
+				time.Sleep(20 * time.Millisecond)
+
+				atomic.AddInt64(&dbUsage, -1)
+				atomic.AddInt64(&dbDone, 1)
+				return nil
+			}, Start)
+			executor.SetStageCost(stageName, Resources{"db": 1})
+			loaders = append(loaders, stageName)
+		}
+
+		executor.Append(stageCPU, func(ctx context.Context) error {
+			atomic.StoreInt64(&cpuRanWith, atomic.LoadInt64(&dbDone))
+			return nil
+		}, Start)
+		executor.SetStageCost(stageCPU, Resources{"cpu": 1})
+
+		executor.SetEnd(append(loaders, stageCPU)...)
+
+		execErr := executor.Run(context.TODO())
+		assert.NoError(t, execErr)
+
+		assert.Len(t, executor.Errs(), 0)
+		assert.Equal(t, int64(dbCapacity), atomic.LoadInt64(&maxDBUsage))
+		assert.GreaterOrEqual(t, atomic.LoadInt64(&cpuRanWith), int64(0))
+		assert.Less(t, atomic.LoadInt64(&cpuRanWith), int64(loaderCount)) // did not wait for all loaders
+
+		assert.Equal(t, []PoolStatus{
+			{Name: "cpu", Capacity: 1, InUse: 0},
+			{Name: "db", Capacity: dbCapacity, InUse: 0},
+		}, executor.Pools())
+	})
+
+	t.Run("pools are visible while running", func(t *testing.T) {
+		const stage1 = StageName("stage-1")
+
+		var pools []PoolStatus
+
+		executor := New()
+		executor.SetPool("db", 3)
+		executor.Append(stage1, func(ctx context.Context) error {
+			pools = executor.Pools()
+			return nil
+		}, Start)
+		executor.SetStageCost(stage1, Resources{"db": 2})
+		executor.SetEnd(stage1)
+
+		execErr := executor.Run(context.TODO())
+		assert.NoError(t, execErr)
+
+		assert.Equal(t, []PoolStatus{{Name: "db", Capacity: 3, InUse: 2}}, pools)
+	})
+
+	t.Run("negative - unknown pool", func(t *testing.T) {
+		executor := New()
+		executor.Append("stage-1", nil, Start)
+		executor.SetStageCost("stage-1", Resources{"db": 1})
+		executor.SetEnd("stage-1")
+
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrPoolUnknown)
+	})
+
+	t.Run("negative - cost exceeds capacity", func(t *testing.T) {
+		executor := New()
+		executor.SetPool("db", 1)
+		executor.Append("stage-1", nil, Start)
+		executor.SetStageCost("stage-1", Resources{"db": 2})
+		executor.SetEnd("stage-1")
+
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrPoolCapacityExceeded)
+	})
+}
+
 func Test_executor_SetFinal(t *testing.T) {
 	t.Parallel()
 
@@ -479,7 +1127,7 @@ func Test_executor_SetFinal(t *testing.T) {
 			spy := NewStageVisitSpy()
 
 			fnWithErr := func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				spy.Append(stage)
 				t.Logf("stage %s is done", stage)
@@ -498,7 +1146,7 @@ func Test_executor_SetFinal(t *testing.T) {
 			executor.Append(stage32, fnWithErr, stage2)
 			executor.SetEnd(stage31, stage32)
 			executor.SetFinal(func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				spy.Append(stage)
 				t.Log("final")
@@ -507,7 +1155,7 @@ func Test_executor_SetFinal(t *testing.T) {
 			})
 
 			execErr := executor.Run(context.TODO())
-			assert.NoError(t, execErr)
+			assert.ErrorIs(t, execErr, ErrStagesFailed)
 
 			assert.Len(t, executor.Errs(), 1)
 
@@ -530,7 +1178,7 @@ func Test_executor_SetFinal(t *testing.T) {
 			spy := NewStageVisitSpy()
 
 			fnNoErr := func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				spy.Append(stage)
 				t.Logf("stage %s is done", stage)
@@ -549,7 +1197,7 @@ func Test_executor_SetFinal(t *testing.T) {
 			executor.Append(stage32, fnNoErr, stage2)
 			executor.SetEnd(stage31, stage32)
 			executor.SetFinal(func(ctx context.Context) error {
-				stage := ctx.Value(ContextKeyStageName).(StageName)
+				stage := StageNameFromContext(ctx)
 
 				spy.Append(stage)
 				t.Log("final")
@@ -571,3 +1219,407 @@ func Test_executor_SetFinal(t *testing.T) {
 		})
 	})
 }
+
+func Test_executorImpl_SetRepanic(t *testing.T) {
+	t.Parallel()
+
+	const (
+		stagePanic  = StageName("stage-panic")
+		stageFast   = StageName("stage-fast")
+		stageAfter  = StageName("stage-after")
+		panicReason = "boom"
+	)
+	// start --> stage-panic -- PANIC --> stage-after --> end
+	//      \--> stage-fast ----------------------------/
+
+	fnPanic := func(ctx context.Context) error {
+		panic(panicReason)
+	}
+	fnFast := func(ctx context.Context) error {
+		return nil
+	}
+
+	t.Run("panic is converted to stage error", func(t *testing.T) {
+		executor := New()
+		executor.SetFailurePolicy(ContinueIndependent)
+		executor.Append(stagePanic, fnPanic, Start)
+		executor.Append(stageFast, fnFast, Start)
+		executor.Append(stageAfter, fnFast, stagePanic)
+		executor.SetEnd(stageFast, stageAfter)
+
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrStagesFailed)
+
+		var panicErr *PanicError
+		if assert.ErrorAs(t, execErr, &panicErr) {
+			assert.Equal(t, panicReason, panicErr.Value)
+			assert.Contains(t, string(panicErr.Stack), "Test_executorImpl_SetRepanic")
+		}
+
+		report := executor.Report()
+		fast, _ := report.Stage(stageFast)
+		assert.Equal(t, Done, fast.State)
+		assert.NoError(t, fast.Err)
+		after, _ := report.Stage(stageAfter)
+		assert.Equal(t, Skipped, after.State)
+		assert.Equal(t, stagePanic, after.SkippedBy)
+	})
+
+	t.Run("panic of stage with timeout", func(t *testing.T) {
+		executor := New()
+		executor.Append(stagePanic, fnPanic, Start)
+		executor.SetStageTimeout(stagePanic, time.Second)
+		executor.SetEnd(stagePanic)
+
+		execErr := executor.Run(context.TODO())
+
+		var panicErr *PanicError
+		assert.ErrorAs(t, execErr, &panicErr)
+	})
+
+	t.Run("panicked stage is retried", func(t *testing.T) {
+		attempts := 0
+
+		executor := New()
+		executor.Append(stagePanic, func(ctx context.Context) error {
+			attempts++
+			if attempts < 2 {
+				panic(panicReason)
+			}
+			return nil
+		}, Start)
+		executor.SetStageRetry(stagePanic, RetryPolicy{MaxAttempts: 2})
+		executor.SetEnd(stagePanic)
+
+		assert.NoError(t, executor.Run(context.TODO()))
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("repanic after cleanup", func(t *testing.T) {
+		finalCalled := false
+
+		executor := New()
+		executor.SetRepanic(true)
+		executor.SetFailurePolicy(ContinueIndependent)
+		executor.Append(stagePanic, fnPanic, Start)
+		executor.Append(stageFast, fnFast, Start)
+		executor.SetEnd(stageFast, stagePanic)
+		executor.SetFinal(func(ctx context.Context) error {
+			finalCalled = true
+			return nil
+		})
+
+		defer func() {
+			r := recover()
+			if r == nil {
+				t.Errorf("The code did not panic")
+				t.FailNow()
+			}
+
+			panicErr, ok := r.(*PanicError)
+			if assert.True(t, ok) {
+				assert.Equal(t, panicReason, panicErr.Value)
+			}
+			assert.True(t, finalCalled)
+			assert.NotNil(t, executor.Report())
+		}()
+
+		_ = executor.Run(context.TODO())
+	})
+}
+
+func Test_executorImpl_AddFinal(t *testing.T) {
+	t.Parallel()
+
+	var (
+		fakeErr  = errors.New("fake error")
+		flushErr = errors.New("flush failed")
+	)
+
+	const (
+		stage1 = StageName("stage-1")
+		stage2 = StageName("stage-2")
+	)
+	// start --> stage-1 -- ERROR --> stage-2 --> end
+
+	fnErr := func(ctx context.Context) error {
+		return fakeErr
+	}
+	fnNoErr := func(ctx context.Context) error {
+		return nil
+	}
+
+	t.Run("finals are called in reverse order with report", func(t *testing.T) {
+		var calls []string
+
+		executor := New()
+		executor.Append(stage1, fnErr, Start)
+		executor.Append(stage2, fnNoErr, stage1)
+		executor.SetEnd(stage2)
+		executor.AddFinal(func(ctx context.Context, report *RunReport) error {
+			calls = append(calls, "release lock")
+			return nil
+		})
+		executor.AddFinal(func(ctx context.Context, report *RunReport) error {
+			calls = append(calls, "flush")
+
+			assert.Equal(t, Final, StageNameFromContext(ctx))
+			if assert.Len(t, report.Failed(), 1) {
+				assert.Equal(t, stage1, report.Failed()[0].Name)
+			}
+			assert.Len(t, report.Skipped(), 2)
+			assert.ErrorIs(t, report.Err, fakeErr)
+
+			return flushErr
+		})
+
+		execErr := executor.Run(context.TODO())
+		assert.Equal(t, []string{"flush", "release lock"}, calls)
+		assert.ErrorIs(t, execErr, ErrStagesFailed)
+		assert.ErrorIs(t, execErr, ErrFinalFailed)
+		assert.ErrorIs(t, execErr, fakeErr)
+		assert.ErrorIs(t, execErr, flushErr)
+		assert.Equal(t, []error{flushErr}, executor.Report().FinalErrs)
+	})
+
+	t.Run("final error fails succeeded run", func(t *testing.T) {
+		executor := New()
+		executor.Append(stage1, fnNoErr, Start)
+		executor.SetEnd(stage1)
+		executor.SetFinal(func(ctx context.Context) error {
+			return flushErr
+		})
+
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrFinalFailed)
+		assert.NotErrorIs(t, execErr, ErrStagesFailed)
+		assert.EqualError(t, execErr, "final callback failed: flush failed")
+	})
+
+	t.Run("panic of final", func(t *testing.T) {
+		called := false
+
+		executor := New()
+		executor.Append(stage1, fnNoErr, Start)
+		executor.SetEnd(stage1)
+		executor.AddFinal(func(ctx context.Context, report *RunReport) error {
+			called = true
+			return nil
+		})
+		executor.AddFinal(func(ctx context.Context, report *RunReport) error {
+			panic("boom")
+		})
+
+		execErr := executor.Run(context.TODO())
+		var panicErr *PanicError
+		assert.ErrorAs(t, execErr, &panicErr)
+		assert.True(t, called)
+	})
+
+	t.Run("SetFinal replaces finals", func(t *testing.T) {
+		var calls []string
+
+		executor := New()
+		executor.Append(stage1, fnNoErr, Start)
+		executor.SetEnd(stage1)
+		executor.AddFinal(func(ctx context.Context, report *RunReport) error {
+			calls = append(calls, "added")
+			return flushErr
+		})
+		executor.SetFinal(func(ctx context.Context) error {
+			calls = append(calls, "set")
+			return nil
+		})
+
+		assert.NoError(t, executor.Run(context.TODO()))
+		assert.Equal(t, []string{"set"}, calls)
+	})
+}
+
+func Test_executorImpl_SetGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	const (
+		stageFast        = StageName("stage-fast")
+		stageCooperative = StageName("stage-cooperative")
+		stageStuck       = StageName("stage-stuck")
+		stageAfter       = StageName("stage-after")
+	)
+	// start --> stage-fast --> stage-cooperative --> stage-after --> end
+
+	fnFast := func(ctx context.Context) error {
+		return nil
+	}
+	fnCooperative := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	fnStuck := func(ctx context.Context) error {
+		time.Sleep(300 * time.Millisecond)
+		return nil
+	}
+
+	t.Run("running stage is cancelled", func(t *testing.T) {
+		var states []State
+
+		executor := New()
+		executor.SetOnChanges(func(stageName StageName, state State, err error) {
+			if stageName == stageCooperative {
+				states = append(states, state)
+			}
+		})
+		executor.Append(stageFast, fnFast, Start)
+		executor.Append(stageCooperative, fnCooperative, stageFast)
+		executor.Append(stageAfter, fnFast, stageCooperative)
+		executor.SetEnd(stageAfter)
+
+		execCtx, execCancel := context.WithCancel(context.TODO())
+		time.AfterFunc(50*time.Millisecond, execCancel)
+
+		execErr := executor.Run(execCtx)
+		assert.ErrorIs(t, execErr, context.Canceled)
+		assert.ErrorContains(t, execErr, "run interrupted after 1 of 4 stages done: context canceled")
+
+		assert.Equal(t, []State{Runnable, Running, Cancelled}, states)
+
+		report := executor.Report()
+		cooperative, _ := report.Stage(stageCooperative)
+		assert.Equal(t, Cancelled, cooperative.State)
+		assert.ErrorIs(t, cooperative.Err, context.Canceled)
+		after, _ := report.Stage(stageAfter)
+		assert.Equal(t, Skipped, after.State)
+	})
+
+	t.Run("nothing is started with done context", func(t *testing.T) {
+		var calls int32
+
+		executor := New()
+		executor.Append(stageFast, func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		}, Start)
+		executor.SetEnd(stageFast)
+
+		execCtx, execCancel := context.WithCancel(context.TODO())
+		execCancel()
+
+		execErr := executor.Run(execCtx)
+		assert.ErrorIs(t, execErr, context.Canceled)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+
+		fast, _ := executor.Report().Stage(stageFast)
+		assert.Equal(t, Skipped, fast.State)
+		assert.Equal(t, "run interrupted: context canceled", fast.SkipReason)
+	})
+
+	t.Run("stuck stage is abandoned after grace period", func(t *testing.T) {
+		executor := New()
+		executor.SetGracePeriod(50 * time.Millisecond)
+		executor.Append(stageStuck, fnStuck, Start)
+		executor.SetEnd(stageStuck)
+
+		execCtx, execCancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+		defer execCancel()
+
+		startedAt := time.Now()
+		execErr := executor.Run(execCtx)
+		assert.Less(t, time.Since(startedAt), 200*time.Millisecond)
+		assert.ErrorIs(t, execErr, context.DeadlineExceeded)
+		assert.ErrorIs(t, execErr, ErrStageAbandoned)
+
+		stuck, _ := executor.Report().Stage(stageStuck)
+		assert.Equal(t, Cancelled, stuck.State)
+		assert.True(t, stuck.Leaked)
+	})
+
+	t.Run("run waits for stages without grace period", func(t *testing.T) {
+		executor := New()
+		executor.Append(stageStuck, fnStuck, Start)
+		executor.SetEnd(stageStuck)
+
+		execCtx, execCancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+		defer execCancel()
+
+		startedAt := time.Now()
+		execErr := executor.Run(execCtx)
+		assert.GreaterOrEqual(t, time.Since(startedAt), 300*time.Millisecond)
+		assert.ErrorIs(t, execErr, context.DeadlineExceeded)
+
+		stuck, _ := executor.Report().Stage(stageStuck)
+		assert.Equal(t, Done, stuck.State) // returned without error
+		assert.False(t, stuck.Leaked)
+	})
+}
+
+func Test_executorImpl_Reset(t *testing.T) {
+	t.Parallel()
+
+	var fakeErr = errors.New("fake error")
+
+	const (
+		stage1 = StageName("stage-1")
+		stage2 = StageName("stage-2")
+	)
+	// start --> stage-1 --> stage-2 --> end
+
+	t.Run("run many times", func(t *testing.T) {
+		var calls int32
+
+		executor := New()
+		executor.Append(stage1, func(ctx context.Context) error {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return fakeErr
+			}
+			return nil
+		}, Start)
+		executor.Append(stage2, func(ctx context.Context) error {
+			Store(ctx).Set("visited", true)
+			return nil
+		}, stage1)
+		executor.SetEnd(stage2)
+
+		assert.ErrorIs(t, executor.Run(context.TODO()), fakeErr)
+		firstReport := executor.Report()
+		assert.Len(t, executor.Errs(), 1)
+
+		assert.NoError(t, executor.Run(context.TODO()))
+		assert.Len(t, executor.Errs(), 0)
+		assert.NotSame(t, firstReport, executor.Report())
+		assert.NotEqual(t, firstReport.RunID, executor.Report().RunID)
+		for _, status := range executor.Status() {
+			assert.Equal(t, Done, status.State, status.Name)
+		}
+		visited, _ := executor.Store().Get("visited")
+		assert.Equal(t, true, visited)
+
+		first, _ := firstReport.Stage(stage2)
+		assert.Equal(t, Skipped, first.State) // report of previous run is not changed
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		release := make(chan struct{})
+
+		executor := New()
+		executor.Append(stage1, func(ctx context.Context) error {
+			<-release
+			return nil
+		}, Start)
+		executor.SetEnd(stage1)
+
+		assert.NoError(t, executor.Reset())
+
+		assert.NoError(t, executor.AsyncRun(context.TODO()))
+		assert.ErrorIs(t, executor.Reset(), ErrRunInProgress)
+
+		close(release)
+		assert.NoError(t, executor.Wait())
+		assert.NotNil(t, executor.Report())
+
+		assert.NoError(t, executor.Reset())
+		assert.Nil(t, executor.Report())
+		assert.Nil(t, executor.Done())
+		assert.ErrorIs(t, executor.Wait(), ErrNotRunYet)
+		assert.Equal(t, []StageStatus{{Name: stage1, State: Runnable}, {Name: End, State: Runnable}}, executor.Status())
+		assert.Empty(t, executor.Errs())
+	})
+}
@@ -0,0 +1,226 @@
+package asyncqu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type ExportFormat string
+
+const (
+	DOT     = ExportFormat("dot")
+	Mermaid = ExportFormat("mermaid")
+)
+
+type ExportOptions struct {
+	WithStates       bool // color stages according to states of the last run
+	WithCriticalPath bool // highlight critical path of the last run and show slack of each stage
+}
+
+// stateColors are fill colors of stages by state, "failed" is used for finished stages with error.
+var stateColors = map[string]string{
+	string(Runnable):    "#eeeeee",
+	string(Running):     "#9ecbff",
+	string(Retrying):    "#ffd591",
+	string(Done):        "#b7eb8f",
+	string(Skipped):     "#d9d9d9",
+	string(Interrupted): "#ffa39e",
+	string(Cancelled):   "#ffe58f",
+	"failed":            "#ff7875",
+}
+
+// Export describes graph of stages including START and END in DOT or Mermaid format.
+// States and critical path are taken from the last run, that is exported as it was compiled.
+func (e *executorImpl) Export(format ExportFormat, opts ExportOptions) (string, error) {
+	if opts.WithStates || opts.WithCriticalPath {
+		if last := e.lastExecution(); last != nil {
+			return last.Export(format, opts)
+		}
+	}
+
+	e.RLock()
+	defer e.RUnlock()
+
+	if opts.WithCriticalPath {
+		return "", ErrNotRunYet
+	}
+
+	return exportGraph(format, stagesWithEnd(e.stagesMap, e.stagesOrder), opts, nil)
+}
+
+// Export describes graph of the execution with states of its stages in DOT or Mermaid format.
+func (x *Execution) Export(format ExportFormat, opts ExportOptions) (string, error) {
+	x.RLock()
+	defer x.RUnlock()
+
+	var path *CriticalPath
+	if opts.WithCriticalPath {
+		var pathErr error
+		if path, pathErr = x.graph.criticalPath(x.report); pathErr != nil {
+			return "", pathErr
+		}
+	}
+
+	return exportGraph(format, x.stages, opts, path)
+}
+
+func exportGraph(format ExportFormat, items []*StageMeta, opts ExportOptions, path *CriticalPath) (string, error) {
+	switch format {
+	case DOT:
+		return exportDOT(items, opts, path), nil
+	case Mermaid:
+		return exportMermaid(items, opts, path), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrExportFormatUnknown, format)
+	}
+}
+
+func exportDOT(items []*StageMeta, opts ExportOptions, path *CriticalPath) string {
+	sb := strings.Builder{}
+
+	sb.WriteString("digraph asyncqu {\n")
+	sb.WriteString("\trankdir=TB;\n")
+	sb.WriteString(fmt.Sprintf("\t%s [shape=circle];\n", dotID(Start)))
+
+	for _, item := range items {
+		attrs := []string{"shape=box"}
+		if item.Name == End {
+			attrs = []string{"shape=doublecircle"}
+		}
+		if opts.WithStates {
+			attrs = append(attrs, "style=filled", fmt.Sprintf("fillcolor=\"%s\"", stateColors[stateClass(item)]))
+		}
+		if path != nil && item.Name != End {
+			label := strings.ReplaceAll(string(item.Name), "\"", "\\\"") + "\\nslack " + path.Slack[item.Name].String()
+			attrs = append(attrs, fmt.Sprintf("label=\"%s\"", label))
+			if path.IsCritical(item.Name) {
+				attrs = append(attrs, "color=red", "penwidth=2")
+			}
+		}
+		sb.WriteString(fmt.Sprintf("\t%s [%s];\n", dotID(item.Name), strings.Join(attrs, ", ")))
+	}
+
+	for _, item := range items {
+		for _, c := range item.Causes {
+			if path != nil && isCriticalEdge(path, c, item.Name) {
+				sb.WriteString(fmt.Sprintf("\t%s -> %s [color=red, penwidth=2];\n", dotID(c), dotID(item.Name)))
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("\t%s -> %s;\n", dotID(c), dotID(item.Name)))
+		}
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+func exportMermaid(items []*StageMeta, opts ExportOptions, path *CriticalPath) string {
+	ids := map[StageName]string{Start: "start"}
+	for i, item := range items {
+		ids[item.Name] = fmt.Sprintf("s%d", i)
+	}
+	ids[End] = "end_"
+	id := func(stageName StageName) string {
+		if nodeID, exists := ids[stageName]; exists {
+			return nodeID
+		}
+		ids[stageName] = fmt.Sprintf("u%d", len(ids)) // unknown cause
+		return ids[stageName]
+	}
+
+	sb := strings.Builder{}
+
+	sb.WriteString("flowchart TB\n")
+	sb.WriteString(fmt.Sprintf("    %s((%s))\n", id(Start), mermaidLabel(Start)))
+
+	for _, item := range items {
+		if item.Name == End {
+			sb.WriteString(fmt.Sprintf("    %s(((%s)))\n", id(End), mermaidLabel(End)))
+			continue
+		}
+		label := mermaidLabel(item.Name)
+		if path != nil {
+			label = strings.TrimSuffix(label, "\"") + "<br/>slack " + path.Slack[item.Name].String() + "\""
+		}
+		sb.WriteString(fmt.Sprintf("    %s[%s]\n", id(item.Name), label))
+	}
+
+	var (
+		edgeIndex     int
+		criticalEdges []string
+	)
+	for _, item := range items {
+		for _, c := range item.Causes {
+			sb.WriteString(fmt.Sprintf("    %s --> %s\n", id(c), id(item.Name)))
+			if path != nil && isCriticalEdge(path, c, item.Name) {
+				criticalEdges = append(criticalEdges, strconv.Itoa(edgeIndex))
+			}
+			edgeIndex++
+		}
+	}
+
+	if opts.WithStates {
+		classes := map[string][]string{}
+		var order []string
+		for _, item := range items {
+			class := stateClass(item)
+			if _, exists := classes[class]; !exists {
+				order = append(order, class)
+			}
+			classes[class] = append(classes[class], id(item.Name))
+		}
+
+		for _, class := range order {
+			sb.WriteString(fmt.Sprintf("    classDef %s fill:%s\n", class, stateColors[class]))
+			sb.WriteString(fmt.Sprintf("    class %s %s\n", strings.Join(classes[class], ","), class))
+		}
+	}
+
+	if path != nil {
+		critical := make([]string, 0, len(path.Stages))
+		for _, stageName := range path.Stages {
+			critical = append(critical, id(stageName))
+		}
+		if len(critical) > 0 {
+			sb.WriteString("    classDef critical stroke:red,stroke-width:3px\n")
+			sb.WriteString(fmt.Sprintf("    class %s critical\n", strings.Join(critical, ",")))
+		}
+		if len(criticalEdges) > 0 {
+			sb.WriteString(fmt.Sprintf("    linkStyle %s stroke:red,stroke-width:3px\n", strings.Join(criticalEdges, ",")))
+		}
+	}
+
+	return sb.String()
+}
+
+// isCriticalEdge reports whether edge from cause to stage belongs to critical path including START and END.
+func isCriticalEdge(path *CriticalPath, cause, stageName StageName) bool {
+	chain := make([]StageName, 0, len(path.Stages)+2)
+	chain = append(chain, Start)
+	chain = append(chain, path.Stages...)
+	chain = append(chain, End)
+
+	for i := 1; i < len(chain); i++ {
+		if chain[i-1] == cause && chain[i] == stageName {
+			return true
+		}
+	}
+	return false
+}
+
+func stateClass(item *StageMeta) string {
+	if isFinished(item.State) && item.Err != nil && item.State != Interrupted && item.State != Cancelled {
+		return "failed"
+	}
+	return string(item.State)
+}
+
+func dotID(stageName StageName) string {
+	return "\"" + strings.ReplaceAll(string(stageName), "\"", "\\\"") + "\""
+}
+
+func mermaidLabel(stageName StageName) string {
+	return "\"" + strings.ReplaceAll(string(stageName), "\"", "#quot;") + "\""
+}
@@ -0,0 +1,90 @@
+package asyncqu
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_executorImpl_Export(t *testing.T) {
+	t.Parallel()
+
+	var fakeErr = errors.New("fake error")
+
+	const (
+		stage1  = StageName("stage-1")
+		stage21 = StageName("stage-2-1")
+		stage22 = StageName("stage-2-2")
+	)
+	// start --> stage-1 --> stage-2-1  --> end
+	//                  \--> stage-2-2 /
+
+	newExecutor := func() Executor {
+		executor := New()
+		executor.Append(stage1, nil, Start)
+		executor.Append(stage21, func(ctx context.Context) error { return fakeErr }, stage1)
+		executor.Append(stage22, nil, stage1)
+		executor.SetEnd(stage21, stage22)
+		return executor
+	}
+
+	t.Run("DOT", func(t *testing.T) {
+		out, err := newExecutor().Export(DOT, ExportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, `digraph asyncqu {
+	rankdir=TB;
+	"start" [shape=circle];
+	"stage-1" [shape=box];
+	"stage-2-1" [shape=box];
+	"stage-2-2" [shape=box];
+	"end" [shape=doublecircle];
+	"start" -> "stage-1";
+	"stage-1" -> "stage-2-1";
+	"stage-1" -> "stage-2-2";
+	"stage-2-1" -> "end";
+	"stage-2-2" -> "end";
+}
+`, out)
+	})
+
+	t.Run("Mermaid", func(t *testing.T) {
+		out, err := newExecutor().Export(Mermaid, ExportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, `flowchart TB
+    start(("start"))
+    s0["stage-1"]
+    s1["stage-2-1"]
+    s2["stage-2-2"]
+    end_((("end")))
+    start --> s0
+    s0 --> s1
+    s0 --> s2
+    s1 --> end_
+    s2 --> end_
+`, out)
+	})
+
+	t.Run("with states after run", func(t *testing.T) {
+		executor := newExecutor()
+		executor.SetFailurePolicy(ContinueIndependent)
+		_ = executor.Run(context.TODO())
+
+		out, err := executor.Export(Mermaid, ExportOptions{WithStates: true})
+		assert.NoError(t, err)
+		assert.Contains(t, out, "    classDef done fill:#b7eb8f\n    class s0,s2 done\n")
+		assert.Contains(t, out, "    classDef failed fill:#ff7875\n    class s1 failed\n")
+		assert.Contains(t, out, "    classDef skipped fill:#d9d9d9\n    class end_ skipped\n")
+
+		out, err = executor.Export(DOT, ExportOptions{WithStates: true})
+		assert.NoError(t, err)
+		assert.Contains(t, out, `"stage-2-1" [shape=box, style=filled, fillcolor="#ff7875"];`)
+		assert.Contains(t, out, `"end" [shape=doublecircle, style=filled, fillcolor="#d9d9d9"];`)
+	})
+
+	t.Run("negative - unknown format", func(t *testing.T) {
+		_, err := newExecutor().Export("svg", ExportOptions{})
+		assert.ErrorIs(t, err, ErrExportFormatUnknown)
+	})
+}
@@ -0,0 +1,107 @@
+package asyncqu
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Graph is a checked and immutable copy of executor: stages and settings without state of any run.
+// It can be executed many times, concurrently too, each execution has its own state and report.
+// Limit of parallel stages and pools are shared by executions, they never run more stages than limits allow.
+type Graph struct {
+	stagesMap   map[StageName]*StageMeta
+	stagesOrder []StageName
+
+	onChangesCb    OnChangedCb
+	onRunChangesCb OnRunChangedCb
+	finals         []FinalFn
+	failurePolicy  FailurePolicy
+	timeout        time.Duration
+	limits         *limits
+	tracer         Tracer
+	metrics        Metrics
+	logger         *slog.Logger
+	logLevels      map[State]slog.Level
+	name           string
+	repanic        bool
+	gracePeriod    time.Duration
+}
+
+// Compile checks the graph and returns its immutable copy, later changes of executor do not affect it.
+func (e *executorImpl) Compile() (*Graph, error) {
+	if !e.hasEnd() {
+		return nil, ErrEndStageIsNotSpecified
+	}
+
+	if resolveErr := e.checkResolved(); resolveErr != nil {
+		return nil, resolveErr
+	}
+
+	if costErr := e.checkCosts(); costErr != nil {
+		return nil, costErr
+	}
+
+	e.RLock()
+	defer e.RUnlock()
+
+	g := &Graph{
+		stagesMap:      make(map[StageName]*StageMeta, len(e.stagesMap)),
+		stagesOrder:    append([]StageName(nil), e.stagesOrder...),
+		onChangesCb:    e.onChangesCb,
+		onRunChangesCb: e.onRunChangesCb,
+		finals:         append([]FinalFn(nil), e.finals...),
+		failurePolicy:  e.failurePolicy,
+		timeout:        e.timeout,
+		limits:         newLimits(e.maxParallel, e.pools),
+		tracer:         e.tracer,
+		metrics:        e.metrics,
+		logger:         e.logger,
+		logLevels:      make(map[State]slog.Level, len(e.logLevels)),
+		name:           e.name,
+		repanic:        e.repanic,
+		gracePeriod:    e.gracePeriod,
+	}
+
+	for stageName, item := range e.stagesMap {
+		def := &StageMeta{
+			Name:    item.Name,
+			Fn:      item.Fn,
+			State:   Runnable,
+			Causes:  append([]StageName(nil), item.Causes...),
+			Timeout: item.Timeout,
+			Retry:   item.Retry,
+		}
+		if item.Cost != nil {
+			def.Cost = make(Resources, len(item.Cost))
+			for poolName, units := range item.Cost {
+				def.Cost[poolName] = units
+			}
+		}
+		g.stagesMap[stageName] = def
+	}
+	for state, level := range e.logLevels {
+		g.logLevels[state] = level
+	}
+
+	return g, nil
+}
+
+// Start executes stages of the graph in background and returns handle of the execution.
+// OnChangedCb is never called concurrently within one execution, but may be called concurrently
+// by executions that run at the same time, OnRunChangedCb tells them apart by run ID.
+func (g *Graph) Start(ctx context.Context) *Execution {
+	x := newExecution(g)
+
+	go func() {
+		runErr := x.run(ctx)
+
+		x.Lock()
+		x.err = runErr
+		x.Unlock()
+
+		close(x.done)
+	}()
+
+	return x
+}
@@ -0,0 +1,209 @@
+package asyncqu
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_executorImpl_Compile(t *testing.T) {
+	t.Parallel()
+
+	var fakeErr = errors.New("fake error")
+
+	const (
+		stage1 = StageName("stage-1")
+		stage2 = StageName("stage-2")
+	)
+	// start --> stage-1 --> stage-2 --> end
+
+	t.Run("concurrent executions have own state", func(t *testing.T) {
+		release := make(chan struct{})
+
+		executor := New()
+		executor.Append(stage1, func(ctx context.Context) error {
+			Store(ctx).Set("run", RunIDFromContext(ctx))
+			<-release
+			return nil
+		}, Start)
+		executor.Append(stage2, func(ctx context.Context) error {
+			return nil
+		}, stage1)
+		executor.SetEnd(stage2)
+
+		graph, compileErr := executor.Compile()
+		if !assert.NoError(t, compileErr) {
+			return
+		}
+
+		executions := make([]*Execution, 3)
+		for i := range executions {
+			executions[i] = graph.Start(context.TODO())
+		}
+
+		for _, x := range executions {
+			x := x
+			assert.Eventually(t, func() bool {
+				return x.Status()[0].State == Running
+			}, time.Second, time.Millisecond)
+			assert.Nil(t, x.Report())
+		}
+		assert.Nil(t, executor.Report()) // executions of compiled graph are not tracked by executor
+
+		close(release)
+
+		runIDs := map[string]struct{}{}
+		for _, x := range executions {
+			assert.NoError(t, x.Wait())
+
+			report := x.Report()
+			if assert.NotNil(t, report) {
+				assert.Equal(t, x.RunID(), report.RunID)
+				assert.Len(t, report.Stages, 3)
+				for _, stage := range report.Stages {
+					assert.Equal(t, Done, stage.State, stage.Name)
+				}
+			}
+
+			runID, _ := x.Store().Get("run")
+			assert.Equal(t, x.RunID(), runID)
+			runIDs[x.RunID()] = struct{}{}
+
+			_, pathErr := x.CriticalPath()
+			assert.NoError(t, pathErr)
+		}
+		assert.Len(t, runIDs, len(executions))
+	})
+
+	t.Run("concurrent executions share limits", func(t *testing.T) {
+		var (
+			running    int64
+			maxRunning int64
+		)
+
+		fnTracked := func(ctx context.Context) error {
+			current := atomic.AddInt64(&running, 1)
+			for {
+				prev := atomic.LoadInt64(&maxRunning)
+				if current <= prev || atomic.CompareAndSwapInt64(&maxRunning, prev, current) {
+					break
+				}
+			}
+
+			time.Sleep(10 * time.Millisecond)
+
+			atomic.AddInt64(&running, -1)
+			return nil
+		}
+
+		executor := New()
+		executor.SetMaxParallel(1)
+		executor.SetPool("db", 1)
+		executor.Append(stage1, fnTracked, Start)
+		executor.SetStageCost(stage1, Resources{"db": 1})
+		executor.Append(stage2, fnTracked, stage1)
+		executor.SetEnd(stage2)
+
+		graph, compileErr := executor.Compile()
+		if !assert.NoError(t, compileErr) {
+			return
+		}
+
+		executions := make([]*Execution, 3)
+		for i := range executions {
+			executions[i] = graph.Start(context.TODO())
+		}
+		for _, x := range executions {
+			assert.NoError(t, x.Wait())
+		}
+
+		assert.Equal(t, int64(1), atomic.LoadInt64(&maxRunning))
+		assert.Equal(t, []PoolStatus{{Name: "db", Capacity: 1, InUse: 0}}, executions[0].Pools())
+	})
+
+	t.Run("callbacks of each execution", func(t *testing.T) {
+		var (
+			mu     sync.Mutex
+			states = map[string][]State{}
+		)
+
+		executor := New()
+		executor.SetOnRunChanges(func(runID string, stageName StageName, state State, err error) {
+			if stageName != stage1 {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			states[runID] = append(states[runID], state)
+		})
+		executor.Append(stage1, nil, Start)
+		executor.SetEnd(stage1)
+
+		graph, compileErr := executor.Compile()
+		if !assert.NoError(t, compileErr) {
+			return
+		}
+
+		executions := []*Execution{graph.Start(context.TODO()), graph.Start(context.TODO())}
+		for _, x := range executions {
+			assert.NoError(t, x.Wait())
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Len(t, states, len(executions))
+		for _, x := range executions {
+			assert.Equal(t, []State{Runnable, Running, Done}, states[x.RunID()])
+		}
+	})
+
+	t.Run("compiled graph is not affected by executor", func(t *testing.T) {
+		var visits sync.Map
+
+		executor := New()
+		executor.Append(stage1, func(ctx context.Context) error {
+			visits.Store(stage1, true)
+			return nil
+		}, Start)
+		executor.SetEnd(stage1)
+
+		graph, compileErr := executor.Compile()
+		if !assert.NoError(t, compileErr) {
+			return
+		}
+
+		executor.Append(stage2, func(ctx context.Context) error {
+			visits.Store(stage2, true)
+			return fakeErr
+		}, stage1)
+		executor.SetEnd(stage2)
+		executor.SetFailurePolicy(FailFast)
+
+		x := graph.Start(context.TODO())
+		assert.NoError(t, x.Wait())
+		assert.Len(t, x.Status(), 2)
+
+		_, visited := visits.Load(stage2)
+		assert.False(t, visited)
+
+		assert.ErrorIs(t, executor.Run(context.TODO()), fakeErr)
+		_, visited = visits.Load(stage2)
+		assert.True(t, visited)
+	})
+
+	t.Run("graph errors", func(t *testing.T) {
+		executor := New()
+		executor.SetForwardRefs(true)
+		executor.Append(stage1, nil, stage2)
+		executor.SetEnd(stage1)
+
+		graph, compileErr := executor.Compile()
+		assert.ErrorIs(t, compileErr, ErrStageWaitForUnknown)
+		assert.Nil(t, graph)
+	})
+}
@@ -0,0 +1,70 @@
+package asyncqu
+
+import "sync"
+
+// limits is usage of parallel slots and pools shared by every execution of graph.
+type limits struct {
+	sync.Mutex
+
+	maxParallel int
+	running     int
+	pools       map[string]*PoolStatus
+	released    chan struct{} // closed and replaced when slot or units are released
+}
+
+func newLimits(maxParallel int, pools map[string]*PoolStatus) *limits {
+	l := &limits{
+		maxParallel: maxParallel,
+		pools:       make(map[string]*PoolStatus, len(pools)),
+		released:    make(chan struct{}),
+	}
+	for poolName, p := range pools {
+		l.pools[poolName] = &PoolStatus{Name: p.Name, Capacity: p.Capacity}
+	}
+
+	return l
+}
+
+// acquire takes parallel slot and units of each pool if all of them are free.
+// Otherwise it returns channel that is closed when any execution releases slot or units.
+func (l *limits) acquire(cost Resources) (bool, <-chan struct{}) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.maxParallel > 0 && l.running >= l.maxParallel {
+		return false, l.released
+	}
+	for poolName, units := range cost {
+		if p := l.pools[poolName]; p.InUse+units > p.Capacity {
+			return false, l.released
+		}
+	}
+
+	l.running++
+	for poolName, units := range cost {
+		l.pools[poolName].InUse += units
+	}
+	return true, nil
+}
+
+// release returns parallel slot and units of each pool and wakes up waiting executions.
+func (l *limits) release(cost Resources) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.running--
+	for poolName, units := range cost {
+		l.pools[poolName].InUse -= units
+	}
+
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// snapshot returns pools sorted by name.
+func (l *limits) snapshot() []PoolStatus {
+	l.Lock()
+	defer l.Unlock()
+
+	return poolsSnapshot(l.pools)
+}
@@ -0,0 +1,66 @@
+package asyncqu
+
+import (
+	"context"
+	"log/slog"
+)
+
+// defaultLogLevels of state transitions, failed stages are logged at least with error level.
+func defaultLogLevels() map[State]slog.Level {
+	return map[State]slog.Level{
+		Runnable:    slog.LevelDebug,
+		Running:     slog.LevelDebug,
+		Retrying:    slog.LevelWarn,
+		Done:        slog.LevelInfo,
+		Skipped:     slog.LevelWarn,
+		Interrupted: slog.LevelWarn,
+		Cancelled:   slog.LevelWarn,
+	}
+}
+
+// Logger returns logger of executor with stage name attribute.
+// It returns slog.Default() if context does not belong to stage.
+func Logger(ctx context.Context) *slog.Logger {
+	if scope, ok := stageScopeFromContext(ctx); ok && scope.logger != nil {
+		return scope.logger
+	}
+	return slog.Default()
+}
+
+// stageLogger returns logger injected into stage context, it falls back to slog.Default() if executor has no logger.
+func (x *Execution) stageLogger(stageName StageName) *slog.Logger {
+	logger := x.graph.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return logger.With(slog.String("run_id", x.runID), slog.String("stage", string(stageName)))
+}
+
+// logTransition logs state change of stage if executor has logger.
+func (x *Execution) logTransition(item *StageMeta, state State, err error) {
+	logger := x.graph.logger
+	if logger == nil {
+		return
+	}
+
+	level := x.graph.logLevels[state]
+	attrs := []slog.Attr{slog.String("run_id", x.runID), slog.String("stage", string(item.Name))}
+
+	switch state {
+	case Retrying:
+		attrs = append(attrs, slog.Int("attempt", item.Attempts))
+	case Done, Interrupted, Cancelled:
+		attrs = append(attrs, slog.Duration("duration", item.FinishedAt.Sub(item.StartedAt)))
+	case Skipped:
+		attrs = append(attrs, slog.String("reason", item.SkipReason))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		if isFinished(state) && level < slog.LevelError {
+			level = slog.LevelError
+		}
+	}
+
+	logger.LogAttrs(context.Background(), level, "stage "+string(state), attrs...)
+}
@@ -0,0 +1,109 @@
+package asyncqu
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_executorImpl_SetLogger(t *testing.T) {
+	t.Parallel()
+
+	var fakeErr = errors.New("fake error")
+
+	const (
+		stage1 = StageName("stage-1")
+		stage2 = StageName("stage-2")
+	)
+	// start --> stage-1 -- ERROR --> stage-2 --> end
+
+	buf := bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	executor := New()
+	executor.SetLogger(logger)
+	executor.SetLogLevel(Running, slog.LevelInfo)
+	executor.Append(stage1, func(ctx context.Context) error {
+		Logger(ctx).Info("inside")
+		return fakeErr
+	}, Start)
+	executor.Append(stage2, func(ctx context.Context) error {
+		return nil
+	}, stage1)
+	executor.SetEnd(stage2)
+
+	_ = executor.Run(context.TODO())
+
+	type record struct {
+		Level  string `json:"level"`
+		Msg    string `json:"msg"`
+		Stage  string `json:"stage"`
+		Error  string `json:"error"`
+		Reason string `json:"reason"`
+	}
+	var records []record
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var r record
+		if assert.NoError(t, json.Unmarshal(scanner.Bytes(), &r)) {
+			records = append(records, r)
+		}
+	}
+
+	find := func(stage StageName, msg string) (record, bool) {
+		for _, r := range records {
+			if r.Stage == string(stage) && r.Msg == msg {
+				return r, true
+			}
+		}
+		return record{}, false
+	}
+
+	t.Run("transitions", func(t *testing.T) {
+		r, ok := find(stage1, "stage runnable")
+		if assert.True(t, ok) {
+			assert.Equal(t, "DEBUG", r.Level)
+		}
+
+		r, ok = find(stage1, "stage running")
+		if assert.True(t, ok) {
+			assert.Equal(t, "INFO", r.Level) // configured
+		}
+
+		r, ok = find(stage1, "stage done")
+		if assert.True(t, ok) {
+			assert.Equal(t, "ERROR", r.Level)
+			assert.Equal(t, fakeErr.Error(), r.Error)
+		}
+
+		r, ok = find(stage2, "stage skipped")
+		if assert.True(t, ok) {
+			assert.Equal(t, "WARN", r.Level)
+			assert.NotEmpty(t, r.Reason)
+		}
+	})
+
+	t.Run("stage logger", func(t *testing.T) {
+		_, ok := find(stage1, "inside")
+		assert.True(t, ok)
+	})
+
+	t.Run("run result", func(t *testing.T) {
+		_, ok := find("", "run failed")
+		assert.True(t, ok)
+	})
+}
+
+func TestLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("outside of stage", func(t *testing.T) {
+		assert.Same(t, slog.Default(), Logger(context.TODO()))
+	})
+}
@@ -0,0 +1,22 @@
+package asyncqu
+
+import "time"
+
+// Metrics receives measurements of runs and stages, adapters export them to particular monitoring systems.
+// Executor name set by SetName is passed to distinguish executors sharing the same Metrics.
+type Metrics interface {
+	// StageStarted is called when stage gets running, queueWait is the time stage was ready but waited for free slot.
+	StageStarted(executor string, stage StageName, queueWait time.Duration)
+	StageSucceeded(executor string, stage StageName, duration time.Duration)
+	StageFailed(executor string, stage StageName, duration time.Duration)
+	StageSkipped(executor string, stage StageName)
+	RunFinished(executor string, duration time.Duration, err error)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) StageStarted(string, StageName, time.Duration)   {}
+func (noopMetrics) StageSucceeded(string, StageName, time.Duration) {}
+func (noopMetrics) StageFailed(string, StageName, time.Duration)    {}
+func (noopMetrics) StageSkipped(string, StageName)                  {}
+func (noopMetrics) RunFinished(string, time.Duration, error)        {}
@@ -0,0 +1,151 @@
+package asyncqu
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMetrics struct {
+	sync.Mutex
+	executors map[string]struct{}
+	started   map[StageName]time.Duration
+	starts    map[StageName]int
+	succeeded map[StageName]time.Duration
+	failed    map[StageName]time.Duration
+	skipped   []StageName
+	runs      []error
+}
+
+func newFakeMetrics() *fakeMetrics {
+	return &fakeMetrics{
+		executors: map[string]struct{}{},
+		started:   map[StageName]time.Duration{},
+		starts:    map[StageName]int{},
+		succeeded: map[StageName]time.Duration{},
+		failed:    map[StageName]time.Duration{},
+	}
+}
+
+func (m *fakeMetrics) StageStarted(executor string, stage StageName, queueWait time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	m.executors[executor] = struct{}{}
+	m.started[stage] = queueWait
+	m.starts[stage]++
+}
+
+func (m *fakeMetrics) StageSucceeded(executor string, stage StageName, duration time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	m.executors[executor] = struct{}{}
+	m.succeeded[stage] = duration
+}
+
+func (m *fakeMetrics) StageFailed(executor string, stage StageName, duration time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	m.executors[executor] = struct{}{}
+	m.failed[stage] = duration
+}
+
+func (m *fakeMetrics) StageSkipped(executor string, stage StageName) {
+	m.Lock()
+	defer m.Unlock()
+	m.executors[executor] = struct{}{}
+	m.skipped = append(m.skipped, stage)
+}
+
+func (m *fakeMetrics) RunFinished(executor string, _ time.Duration, err error) {
+	m.Lock()
+	defer m.Unlock()
+	m.executors[executor] = struct{}{}
+	m.runs = append(m.runs, err)
+}
+
+func Test_executorImpl_SetMetrics(t *testing.T) {
+	t.Parallel()
+
+	var fakeErr = errors.New("fake error")
+
+	const (
+		stage11 = StageName("stage-1-1")
+		stage12 = StageName("stage-1-2")
+		stage2  = StageName("stage-2")
+	)
+	// start --> stage-1-1 ------------------------> end
+	//      \--> stage-1-2 -- ERROR --> stage-2 --/
+
+	metrics := newFakeMetrics()
+
+	executor := New()
+	executor.SetName("pipeline")
+	executor.SetMetrics(metrics)
+	executor.SetMaxParallel(1)
+	executor.SetFailurePolicy(ContinueIndependent)
+	executor.Append(stage11, func(ctx context.Context) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}, Start)
+	executor.Append(stage12, func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return fakeErr
+	}, Start)
+	executor.Append(stage2, func(ctx context.Context) error {
+		return nil
+	}, stage12)
+	executor.SetEnd(stage11, stage2)
+
+	runErr := executor.Run(context.TODO())
+	assert.ErrorIs(t, runErr, fakeErr)
+
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	t.Run("executor name", func(t *testing.T) {
+		assert.Equal(t, map[string]struct{}{"pipeline": {}}, metrics.executors)
+	})
+
+	t.Run("queue wait", func(t *testing.T) {
+		assert.Less(t, metrics.started[stage11], 10*time.Millisecond)
+		assert.GreaterOrEqual(t, metrics.started[stage12], 50*time.Millisecond)
+	})
+
+	t.Run("outcomes", func(t *testing.T) {
+		assert.Contains(t, metrics.succeeded, stage11)
+		assert.GreaterOrEqual(t, metrics.succeeded[stage11], 50*time.Millisecond)
+		assert.Contains(t, metrics.failed, stage12)
+		assert.ElementsMatch(t, []StageName{stage2, End}, metrics.skipped)
+		if assert.Len(t, metrics.runs, 1) {
+			assert.ErrorIs(t, metrics.runs[0], fakeErr)
+		}
+	})
+
+	t.Run("retries are started once", func(t *testing.T) {
+		retryMetrics := newFakeMetrics()
+
+		retryExecutor := New()
+		retryExecutor.SetMetrics(retryMetrics)
+		retryExecutor.Append(stage11, func(ctx context.Context) error {
+			if AttemptFromContext(ctx) < 3 {
+				return fakeErr
+			}
+			return nil
+		}, Start)
+		retryExecutor.SetStageRetry(stage11, RetryPolicy{MaxAttempts: 3})
+		retryExecutor.SetEnd(stage11)
+
+		assert.NoError(t, retryExecutor.Run(context.TODO()))
+
+		retryMetrics.Lock()
+		defer retryMetrics.Unlock()
+
+		assert.Equal(t, map[StageName]int{stage11: 1, End: 1}, retryMetrics.starts)
+		assert.Contains(t, retryMetrics.succeeded, stage11)
+		assert.NotContains(t, retryMetrics.failed, stage11)
+	})
+}
@@ -0,0 +1,24 @@
+module github.com/goforbroke1006/asyncqu/otelasyncqu
+
+go 1.21
+
+// core module is used from the parent directory until the release this adapter is developed with is tagged
+replace github.com/goforbroke1006/asyncqu => ../
+
+require (
+	github.com/goforbroke1006/asyncqu v0.2.0
+	github.com/stretchr/testify v1.8.4
+	go.opentelemetry.io/otel v1.19.0
+	go.opentelemetry.io/otel/sdk v1.19.0
+	go.opentelemetry.io/otel/trace v1.19.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.opentelemetry.io/otel/metric v1.19.0 // indirect
+	golang.org/x/sys v0.12.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,71 @@
+// Package otelasyncqu adapts OpenTelemetry tracer to asyncqu.Tracer.
+package otelasyncqu
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/goforbroke1006/asyncqu"
+)
+
+// NewTracer wraps OpenTelemetry tracer, e.g. otel.Tracer("asyncqu").
+func NewTracer(tracer trace.Tracer) asyncqu.Tracer {
+	return &tracerAdapter{tracer: tracer}
+}
+
+type tracerAdapter struct {
+	tracer trace.Tracer
+}
+
+func (t *tracerAdapter) Start(ctx context.Context, name string, attrs ...asyncqu.Attr) (context.Context, asyncqu.Span) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithAttributes(convert(attrs)...))
+	return ctx, &spanAdapter{span: span}
+}
+
+type spanAdapter struct {
+	span trace.Span
+}
+
+func (s *spanAdapter) SetAttributes(attrs ...asyncqu.Attr) {
+	s.span.SetAttributes(convert(attrs)...)
+}
+
+func (s *spanAdapter) AddEvent(name string, attrs ...asyncqu.Attr) {
+	s.span.AddEvent(name, trace.WithAttributes(convert(attrs)...))
+}
+
+func (s *spanAdapter) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *spanAdapter) End() {
+	s.span.End()
+}
+
+func convert(attrs []asyncqu.Attr) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(a.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(a.Key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(a.Key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(a.Key, v))
+		case float64:
+			kvs = append(kvs, attribute.Float64(a.Key, v))
+		case []string:
+			kvs = append(kvs, attribute.StringSlice(a.Key, v))
+		default:
+			kvs = append(kvs, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+	return kvs
+}
@@ -0,0 +1,104 @@
+package otelasyncqu
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/goforbroke1006/asyncqu"
+)
+
+func TestNewTracer(t *testing.T) {
+	t.Parallel()
+
+	var fakeErr = errors.New("fake error")
+
+	const (
+		stage1 = asyncqu.StageName("stage-1")
+		stage2 = asyncqu.StageName("stage-2")
+		stage3 = asyncqu.StageName("stage-3")
+	)
+	// start --> stage-1 -- ERROR --> stage-3 --> end
+	//      \--> stage-2 ----------------------/
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otelTracer := provider.Tracer("test")
+
+	executor := asyncqu.New()
+	executor.SetTracer(NewTracer(otelTracer))
+	executor.SetFailurePolicy(asyncqu.ContinueIndependent)
+	executor.Append(stage1, func(ctx context.Context) error {
+		return fakeErr
+	}, asyncqu.Start)
+	executor.Append(stage2, func(ctx context.Context) error {
+		_, span := otelTracer.Start(ctx, "user-span")
+		span.End()
+		return nil
+	}, asyncqu.Start)
+	executor.Append(stage3, func(ctx context.Context) error {
+		return nil
+	}, stage1)
+	executor.SetEnd(stage2, stage3)
+
+	runErr := executor.Run(context.TODO())
+	assert.ErrorIs(t, runErr, fakeErr)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+
+	run, ok := spans[asyncqu.SpanNameRun]
+	if !assert.True(t, ok) {
+		return
+	}
+
+	t.Run("stage spans are children of run", func(t *testing.T) {
+		for _, name := range []asyncqu.StageName{stage1, stage2} {
+			span, ok := spans[string(name)]
+			if assert.True(t, ok, name) {
+				assert.Equal(t, run.SpanContext().SpanID(), span.Parent().SpanID(), name)
+				assert.Equal(t, run.SpanContext().TraceID(), span.SpanContext().TraceID(), name)
+			}
+		}
+	})
+
+	t.Run("user span nests under stage", func(t *testing.T) {
+		span, ok := spans["user-span"]
+		if assert.True(t, ok) {
+			assert.Equal(t, spans[string(stage2)].SpanContext().SpanID(), span.Parent().SpanID())
+		}
+	})
+
+	t.Run("attributes and error", func(t *testing.T) {
+		span := spans[string(stage1)]
+		assert.Contains(t, span.Attributes(), attribute.String(asyncqu.AttrStageName, string(stage1)))
+		assert.Contains(t, span.Attributes(), attribute.StringSlice(asyncqu.AttrStageCauses, []string{string(asyncqu.Start)}))
+		assert.Contains(t, span.Attributes(), attribute.Int(asyncqu.AttrAttempt, 1))
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Equal(t, codes.Error, run.Status().Code)
+	})
+
+	t.Run("skipped stage is run event", func(t *testing.T) {
+		var skipped []string
+		for _, ev := range run.Events() {
+			if ev.Name != asyncqu.EventStageSkipped {
+				continue
+			}
+			for _, a := range ev.Attributes {
+				if a.Key == asyncqu.AttrStageName {
+					skipped = append(skipped, a.Value.AsString())
+				}
+			}
+		}
+		assert.Equal(t, []string{string(stage3), string(asyncqu.End)}, skipped)
+		assert.NotContains(t, spans, string(stage3))
+	})
+}
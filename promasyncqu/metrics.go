@@ -0,0 +1,114 @@
+// Package promasyncqu exports asyncqu metrics to Prometheus.
+package promasyncqu
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/goforbroke1006/asyncqu"
+)
+
+const namespace = "asyncqu"
+
+// NewMetrics creates collectors and registers them in reg, e.g. prometheus.DefaultRegisterer.
+func NewMetrics(reg prometheus.Registerer) (asyncqu.Metrics, error) {
+	stageLabels := []string{"executor", "stage"}
+
+	m := &metrics{
+		started: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "stages_started_total",
+			Help:      "Count of started stages.",
+		}, stageLabels),
+		succeeded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "stages_succeeded_total",
+			Help:      "Count of stages finished without error.",
+		}, stageLabels),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "stages_failed_total",
+			Help:      "Count of stages finished with error.",
+		}, stageLabels),
+		skipped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "stages_skipped_total",
+			Help:      "Count of stages that were not started.",
+		}, stageLabels),
+		running: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "stages_running",
+			Help:      "Count of currently running stages.",
+		}, []string{"executor"}),
+		stageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "stage_duration_seconds",
+			Help:      "Duration of stage including retries.",
+			Buckets:   prometheus.DefBuckets,
+		}, stageLabels),
+		queueWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "stage_queue_wait_seconds",
+			Help:      "Time stage was ready but waited for free slot.",
+			Buckets:   prometheus.DefBuckets,
+		}, stageLabels),
+		runDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "run_duration_seconds",
+			Help:      "Duration of run.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"executor", "status"}),
+	}
+
+	for _, c := range []prometheus.Collector{
+		m.started, m.succeeded, m.failed, m.skipped, m.running, m.stageDuration, m.queueWait, m.runDuration,
+	} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+type metrics struct {
+	started       *prometheus.CounterVec
+	succeeded     *prometheus.CounterVec
+	failed        *prometheus.CounterVec
+	skipped       *prometheus.CounterVec
+	running       *prometheus.GaugeVec
+	stageDuration *prometheus.HistogramVec
+	queueWait     *prometheus.HistogramVec
+	runDuration   *prometheus.HistogramVec
+}
+
+func (m *metrics) StageStarted(executor string, stage asyncqu.StageName, queueWait time.Duration) {
+	m.started.WithLabelValues(executor, string(stage)).Inc()
+	m.queueWait.WithLabelValues(executor, string(stage)).Observe(queueWait.Seconds())
+	m.running.WithLabelValues(executor).Inc()
+}
+
+func (m *metrics) StageSucceeded(executor string, stage asyncqu.StageName, duration time.Duration) {
+	m.succeeded.WithLabelValues(executor, string(stage)).Inc()
+	m.stageDuration.WithLabelValues(executor, string(stage)).Observe(duration.Seconds())
+	m.running.WithLabelValues(executor).Dec()
+}
+
+func (m *metrics) StageFailed(executor string, stage asyncqu.StageName, duration time.Duration) {
+	m.failed.WithLabelValues(executor, string(stage)).Inc()
+	m.stageDuration.WithLabelValues(executor, string(stage)).Observe(duration.Seconds())
+	m.running.WithLabelValues(executor).Dec()
+}
+
+func (m *metrics) StageSkipped(executor string, stage asyncqu.StageName) {
+	m.skipped.WithLabelValues(executor, string(stage)).Inc()
+}
+
+func (m *metrics) RunFinished(executor string, duration time.Duration, err error) {
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	m.runDuration.WithLabelValues(executor, status).Observe(duration.Seconds())
+}
@@ -0,0 +1,75 @@
+package promasyncqu
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/goforbroke1006/asyncqu"
+)
+
+func TestNewMetrics(t *testing.T) {
+	t.Parallel()
+
+	var fakeErr = errors.New("fake error")
+
+	const (
+		stage11 = asyncqu.StageName("stage-1-1")
+		stage12 = asyncqu.StageName("stage-1-2")
+		stage2  = asyncqu.StageName("stage-2")
+	)
+	// start --> stage-1-1 ------------------------> end
+	//      \--> stage-1-2 -- ERROR --> stage-2 --/
+
+	reg := prometheus.NewRegistry()
+	adapter, err := NewMetrics(reg)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	executor := asyncqu.New()
+	executor.SetName("pipeline")
+	executor.SetMetrics(adapter)
+	executor.SetFailurePolicy(asyncqu.ContinueIndependent)
+	executor.Append(stage11, func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}, asyncqu.Start)
+	executor.Append(stage12, func(ctx context.Context) error {
+		return fakeErr
+	}, asyncqu.Start)
+	executor.Append(stage2, func(ctx context.Context) error {
+		return nil
+	}, stage12)
+	executor.SetEnd(stage11, stage2)
+
+	runErr := executor.Run(context.TODO())
+	assert.ErrorIs(t, runErr, fakeErr)
+
+	t.Run("counters", func(t *testing.T) {
+		m := adapter.(*metrics)
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.started.WithLabelValues("pipeline", string(stage11))))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.started.WithLabelValues("pipeline", string(stage12))))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.succeeded.WithLabelValues("pipeline", string(stage11))))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.failed.WithLabelValues("pipeline", string(stage12))))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.skipped.WithLabelValues("pipeline", string(stage2))))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.skipped.WithLabelValues("pipeline", string(asyncqu.End))))
+		assert.Equal(t, 0.0, testutil.ToFloat64(m.running.WithLabelValues("pipeline")))
+	})
+
+	t.Run("histograms", func(t *testing.T) {
+		assert.Equal(t, 2, testutil.CollectAndCount(reg, "asyncqu_stage_duration_seconds"))
+		assert.Equal(t, 2, testutil.CollectAndCount(reg, "asyncqu_stage_queue_wait_seconds"))
+		assert.Equal(t, 1, testutil.CollectAndCount(reg, "asyncqu_run_duration_seconds"))
+	})
+
+	t.Run("register twice", func(t *testing.T) {
+		_, err := NewMetrics(reg)
+		assert.Error(t, err)
+	})
+}
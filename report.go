@@ -0,0 +1,124 @@
+package asyncqu
+
+import (
+	"sort"
+	"time"
+)
+
+// RunReport describes the run and every stage of it.
+type RunReport struct {
+	RunID      string // the same ID that RunIDFromContext returns in stages of the run
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Duration   time.Duration
+	Err        error         // the same error that Run returned
+	Stages     []StageReport // in order stages were appended, END stage is the last one
+	FinalErrs  []error       // errors of final callbacks in order they were called
+}
+
+type StageReport struct {
+	Name        StageName
+	Causes      []StageName
+	State       State
+	Err         error
+	StartedAt   time.Time // zero if stage was not started
+	FinishedAt  time.Time
+	Duration    time.Duration
+	Attempts    int
+	AttemptErrs []error   // errors of each failed attempt
+	Leaked      bool      // stage function ignored timeout or cancellation and was still running when executor gave up
+	SkippedBy   StageName // cause that failed or was skipped, or failed stage that stopped scheduling
+	SkipReason  string
+}
+
+// Stage returns report of stage by name.
+func (r *RunReport) Stage(stageName StageName) (StageReport, bool) {
+	for _, s := range r.Stages {
+		if s.Name == stageName {
+			return s, true
+		}
+	}
+	return StageReport{}, false
+}
+
+// Failed returns reports of stages finished with error.
+func (r *RunReport) Failed() []StageReport {
+	return r.filter(func(s StageReport) bool { return s.Err != nil })
+}
+
+// Skipped returns reports of stages that were not started.
+func (r *RunReport) Skipped() []StageReport {
+	return r.filter(func(s StageReport) bool { return s.State == Skipped })
+}
+
+func (r *RunReport) filter(match func(s StageReport) bool) []StageReport {
+	var stages []StageReport
+	for _, s := range r.Stages {
+		if match(s) {
+			stages = append(stages, s)
+		}
+	}
+	return stages
+}
+
+// summary returns error that describes failed stages, final callbacks and context cancellation,
+// nil if run is succeeded.
+func (r *RunReport) summary(ctxErr error) error {
+	failed := r.Failed()
+	if ctxErr == nil && len(failed) == 0 && len(r.FinalErrs) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(failed, func(i, j int) bool {
+		return failed[i].FinishedAt.Before(failed[j].FinishedAt)
+	})
+
+	runErr := &RunError{
+		Stages:  make([]*StageError, 0, len(failed)),
+		Skipped: len(r.Skipped()),
+		Done:    len(r.filter(func(s StageReport) bool { return s.State == Done && s.Err == nil })),
+		Total:   len(r.Stages),
+		Cause:   ctxErr,
+		Final:   r.FinalErrs,
+	}
+	for _, s := range failed {
+		if s.State == Interrupted {
+			runErr.Interrupted++
+			continue
+		}
+		runErr.Stages = append(runErr.Stages, &StageError{Stage: s.Name, Attempt: s.Attempts, Err: s.Err})
+	}
+
+	return runErr
+}
+
+func (x *Execution) buildReport(startedAt, finishedAt time.Time) *RunReport {
+	report := &RunReport{
+		RunID:      x.runID,
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		Duration:   finishedAt.Sub(startedAt),
+	}
+
+	for _, item := range x.stages {
+		stage := StageReport{
+			Name:        item.Name,
+			Causes:      item.Causes,
+			State:       item.State,
+			Err:         item.Err,
+			StartedAt:   item.StartedAt,
+			FinishedAt:  item.FinishedAt,
+			Attempts:    item.Attempts,
+			AttemptErrs: item.AttemptErrs,
+			Leaked:      item.Leaked,
+			SkippedBy:   item.SkippedBy,
+			SkipReason:  item.SkipReason,
+		}
+		if !item.StartedAt.IsZero() {
+			stage.Duration = item.FinishedAt.Sub(item.StartedAt)
+		}
+		report.Stages = append(report.Stages, stage)
+	}
+
+	return report
+}
@@ -0,0 +1,164 @@
+package asyncqu
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_executorImpl_Report(t *testing.T) {
+	t.Parallel()
+
+	var fakeErr = errors.New("fake error")
+
+	const (
+		stage1  = StageName("stage-1")
+		stage21 = StageName("stage-2-1")
+		stage22 = StageName("stage-2-2")
+		stage3  = StageName("stage-3")
+	)
+	// start --> stage-1 --> stage-2-1 -- ERROR --> stage-3 --> end
+	//                  \--> stage-2-2 -----------------------/
+
+	t.Run("before run", func(t *testing.T) {
+		assert.Nil(t, New().Report())
+	})
+
+	t.Run("failed run", func(t *testing.T) {
+		executor := New()
+		executor.SetFailurePolicy(ContinueIndependent)
+		executor.Append(stage1, func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}, Start)
+		executor.Append(stage21, func(ctx context.Context) error {
+			return fakeErr
+		}, stage1)
+		executor.Append(stage22, func(ctx context.Context) error {
+			return nil
+		}, stage1)
+		executor.Append(stage3, nil, stage21)
+		executor.SetEnd(stage3, stage22)
+
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrStagesFailed)
+		assert.EqualError(t, execErr, "stages failed: 1 failed, 2 skipped: stage 'stage-2-1': fake error")
+
+		report := executor.Report()
+		if !assert.NotNil(t, report) {
+			return
+		}
+		assert.Equal(t, execErr, report.Err)
+		assert.False(t, report.StartedAt.After(report.FinishedAt))
+
+		names := make([]StageName, 0, len(report.Stages))
+		for _, s := range report.Stages {
+			names = append(names, s.Name)
+		}
+		assert.Equal(t, []StageName{stage1, stage21, stage22, stage3, End}, names)
+
+		s1, _ := report.Stage(stage1)
+		assert.Equal(t, Done, s1.State)
+		assert.Equal(t, 1, s1.Attempts)
+		assert.GreaterOrEqual(t, s1.Duration, 10*time.Millisecond)
+
+		s21, _ := report.Stage(stage21)
+		assert.Equal(t, Done, s21.State)
+		assert.Equal(t, fakeErr, s21.Err)
+		assert.False(t, s21.StartedAt.Before(s1.FinishedAt))
+
+		s3, _ := report.Stage(stage3)
+		assert.Equal(t, Skipped, s3.State)
+		assert.Equal(t, stage21, s3.SkippedBy)
+		assert.Equal(t, "cause 'stage-2-1' failed", s3.SkipReason)
+		assert.True(t, s3.StartedAt.IsZero())
+
+		end, _ := report.Stage(End)
+		assert.Equal(t, stage3, end.SkippedBy)
+		assert.Equal(t, "cause 'stage-3' skipped", end.SkipReason)
+
+		assert.Len(t, report.Failed(), 1)
+		assert.Len(t, report.Skipped(), 2)
+	})
+
+	t.Run("scheduling stopped", func(t *testing.T) {
+		executor := New()
+		executor.Append(stage1, func(ctx context.Context) error { return fakeErr }, Start)
+		executor.Append(stage21, func(ctx context.Context) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		}, Start)
+		executor.Append(stage3, nil, stage21)
+		executor.SetEnd(stage1, stage3)
+
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrStagesFailed)
+
+		s3, _ := executor.Report().Stage(stage3)
+		assert.Equal(t, Skipped, s3.State)
+		assert.Equal(t, stage1, s3.SkippedBy)
+		assert.Equal(t, "scheduling stopped after 'stage-1' failed", s3.SkipReason)
+	})
+
+	t.Run("cancelled run", func(t *testing.T) {
+		executor := New()
+		executor.Append(stage1, func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}, Start)
+		executor.Append(stage21, nil, stage1)
+		executor.SetEnd(stage21)
+
+		execCtx, execCancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+		defer execCancel()
+
+		execErr := executor.Run(execCtx)
+		assert.ErrorIs(t, execErr, context.DeadlineExceeded)
+		assert.NotErrorIs(t, execErr, ErrStagesFailed)
+
+		s21, _ := executor.Report().Stage(stage21)
+		assert.Equal(t, Skipped, s21.State)
+		assert.Equal(t, "run interrupted: context deadline exceeded", s21.SkipReason)
+	})
+
+	t.Run("context done after all stages finished", func(t *testing.T) {
+		execCtx, execCancel := context.WithCancel(context.TODO())
+		defer execCancel()
+
+		executor := New()
+		executor.SetOnChanges(func(stageName StageName, state State, err error) {
+			if stageName == End && state == Done {
+				execCancel()
+			}
+		})
+		executor.Append(stage1, nil, Start)
+		executor.SetEnd(stage1)
+
+		assert.NoError(t, executor.Run(execCtx))
+		assert.NoError(t, executor.Report().Err)
+	})
+
+	t.Run("durations are measured by stages", func(t *testing.T) {
+		executor := New()
+		executor.SetOnChanges(func(stageName StageName, state State, err error) {
+			if stageName == stage1 && state == Done {
+				time.Sleep(100 * time.Millisecond) // scheduler is busy while stage-2-2 finishes
+			}
+		})
+		executor.Append(stage1, nil, Start)
+		executor.Append(stage22, func(ctx context.Context) error {
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		}, Start)
+		executor.SetEnd(stage1, stage22)
+
+		assert.NoError(t, executor.Run(context.TODO()))
+
+		s22, _ := executor.Report().Stage(stage22)
+		assert.GreaterOrEqual(t, s22.Duration, 20*time.Millisecond)
+		assert.Less(t, s22.Duration, 90*time.Millisecond)
+	})
+}
@@ -0,0 +1,76 @@
+package asyncqu
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Produce wraps function that returns a value, so stages that wait for this one can read it with Result.
+func Produce[T any](fn func(ctx context.Context) (T, error)) StageFn {
+	return func(ctx context.Context) error {
+		value, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+
+		scope, ok := stageScopeFromContext(ctx)
+		if !ok {
+			return ErrResultOutsideOfStage
+		}
+
+		scope.results.set(scope.name, value)
+		return nil
+	}
+}
+
+// Result returns value produced by stage that is a cause of the current one.
+func Result[T any](ctx context.Context, stageName StageName) (T, error) {
+	var zero T
+
+	scope, ok := stageScopeFromContext(ctx)
+	if !ok {
+		return zero, ErrResultOutsideOfStage
+	}
+
+	if !scope.isCause(stageName) {
+		return zero, fmt.Errorf("stage '%s' reads result of '%s': %w", scope.name, stageName, ErrResultOfNonCause)
+	}
+
+	raw, exists := scope.results.get(stageName)
+	if !exists {
+		return zero, fmt.Errorf("stage '%s' reads result of '%s': %w", scope.name, stageName, ErrResultMissing)
+	}
+
+	value, ok := raw.(T)
+	if !ok {
+		return zero, fmt.Errorf("stage '%s' reads result of '%s': %w: got %T, want %s",
+			scope.name, stageName, ErrResultTypeMismatch, raw, reflect.TypeOf((*T)(nil)).Elem())
+	}
+
+	return value, nil
+}
+
+func newResultsStore() *resultsStore {
+	return &resultsStore{values: map[StageName]any{}}
+}
+
+type resultsStore struct {
+	values   map[StageName]any
+	valuesMx sync.RWMutex
+}
+
+func (s *resultsStore) set(stageName StageName, value any) {
+	s.valuesMx.Lock()
+	s.values[stageName] = value
+	s.valuesMx.Unlock()
+}
+
+func (s *resultsStore) get(stageName StageName) (any, bool) {
+	s.valuesMx.RLock()
+	value, exists := s.values[stageName]
+	s.valuesMx.RUnlock()
+
+	return value, exists
+}
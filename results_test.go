@@ -0,0 +1,118 @@
+package asyncqu
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResult(t *testing.T) {
+	t.Parallel()
+
+	const (
+		stageLoad   = StageName("stage-load")
+		stageFilter = StageName("stage-filter")
+		stageCount  = StageName("stage-count")
+	)
+	// start --> stage-load --> stage-filter --> stage-count --> end
+
+	t.Run("positive", func(t *testing.T) {
+		var count int
+
+		executor := New()
+		executor.Append(stageLoad, Produce(func(ctx context.Context) ([]string, error) {
+			return []string{"alice", "bob", "admin"}, nil
+		}), Start)
+		executor.Append(stageFilter, Produce(func(ctx context.Context) ([]string, error) {
+			users, err := Result[[]string](ctx, stageLoad)
+			if err != nil {
+				return nil, err
+			}
+
+			filtered := make([]string, 0, len(users))
+			for _, u := range users {
+				if !strings.HasPrefix(u, "admin") {
+					filtered = append(filtered, u)
+				}
+			}
+			return filtered, nil
+		}), stageLoad)
+		executor.Append(stageCount, func(ctx context.Context) error {
+			users, err := Result[[]string](ctx, stageFilter)
+			count = len(users)
+			return err
+		}, stageFilter)
+		executor.SetEnd(stageCount)
+
+		execErr := executor.Run(context.TODO())
+		assert.NoError(t, execErr)
+
+		assert.Len(t, executor.Errs(), 0)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		run := func(t *testing.T, read StageFn) error {
+			executor := New()
+			executor.Append(stageLoad, Produce(func(ctx context.Context) (int, error) {
+				return 42, nil
+			}), Start)
+			executor.Append(stageFilter, func(ctx context.Context) error { return nil }, stageLoad)
+			executor.Append(stageCount, read, stageFilter)
+			executor.SetEnd(stageCount)
+
+			execErr := executor.Run(context.TODO())
+			assert.ErrorIs(t, execErr, ErrStagesFailed)
+
+			errs := executor.Errs()
+			if !assert.Len(t, errs, 1) {
+				return nil
+			}
+			return errs[0]
+		}
+
+		t.Run("read result of non-cause stage", func(t *testing.T) {
+			err := run(t, func(ctx context.Context) error {
+				_, err := Result[int](ctx, stageLoad)
+				return err
+			})
+			assert.ErrorIs(t, err, ErrResultOfNonCause)
+		})
+
+		t.Run("cause has no result", func(t *testing.T) {
+			err := run(t, func(ctx context.Context) error {
+				_, err := Result[int](ctx, stageFilter)
+				return err
+			})
+			assert.ErrorIs(t, err, ErrResultMissing)
+		})
+
+		t.Run("type mismatch", func(t *testing.T) {
+			executor := New()
+			executor.Append(stageLoad, Produce(func(ctx context.Context) (int, error) {
+				return 42, nil
+			}), Start)
+			executor.Append(stageCount, func(ctx context.Context) error {
+				_, err := Result[string](ctx, stageLoad)
+				return err
+			}, stageLoad)
+			executor.SetEnd(stageCount)
+
+			execErr := executor.Run(context.TODO())
+			assert.ErrorIs(t, execErr, ErrStagesFailed)
+
+			errs := executor.Errs()
+			if assert.Len(t, errs, 1) {
+				assert.ErrorIs(t, errs[0], ErrResultTypeMismatch)
+				assert.Contains(t, errs[0].Error(), "got int, want string")
+			}
+		})
+
+		t.Run("outside of stage", func(t *testing.T) {
+			_, err := Result[int](context.TODO(), stageLoad)
+			assert.ErrorIs(t, err, ErrResultOutsideOfStage)
+		})
+	})
+}
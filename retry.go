@@ -0,0 +1,64 @@
+package asyncqu
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy describes how failed stage should be restarted.
+type RetryPolicy struct {
+	MaxAttempts int                  // total number of attempts including the first one
+	Backoff     Backoff              // delay before next attempt, nil means retry immediately
+	Retryable   func(err error) bool // nil means every error is retryable
+}
+
+// Backoff returns delay before the next attempt, attempt is the number of failed attempt starting from 1.
+type Backoff func(attempt int) time.Duration
+
+// ConstantBackoff returns the same delay for each attempt,
+// jitter is a fraction of delay (clamped to 0..1) that is randomly added or subtracted.
+func ConstantBackoff(delay time.Duration, jitter float64) Backoff {
+	return func(attempt int) time.Duration {
+		return withJitter(delay, jitter)
+	}
+}
+
+// ExponentialBackoff doubles delay for each attempt starting from initial and never exceeds limit,
+// zero limit means no limit, then delay saturates at the maximum duration.
+// Jitter is a fraction of delay (clamped to 0..1) that is randomly added or subtracted.
+func ExponentialBackoff(initial, limit time.Duration, jitter float64) Backoff {
+	return func(attempt int) time.Duration {
+		delay := initial
+		for i := 1; i < attempt; i++ {
+			if limit > 0 && delay >= limit {
+				break
+			}
+			if delay > math.MaxInt64/2 {
+				delay = math.MaxInt64 // saturate instead of overflow
+				break
+			}
+			delay *= 2
+		}
+
+		if limit > 0 && delay > limit {
+			delay = limit
+		}
+
+		return withJitter(delay, jitter)
+	}
+}
+
+// withJitter randomizes delay by fraction of it, jitter is clamped to 0..1.
+func withJitter(delay time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 || delay <= 0 {
+		return delay
+	}
+	jitter = math.Min(jitter, 1)
+
+	jittered := float64(delay) + float64(delay)*jitter*(2*rand.Float64()-1)
+	if jittered >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(jittered)
+}
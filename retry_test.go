@@ -0,0 +1,73 @@
+package asyncqu
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstantBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("without jitter", func(t *testing.T) {
+		backoff := ConstantBackoff(100*time.Millisecond, 0)
+		assert.Equal(t, 100*time.Millisecond, backoff(1))
+		assert.Equal(t, 100*time.Millisecond, backoff(10))
+	})
+
+	t.Run("with jitter", func(t *testing.T) {
+		backoff := ConstantBackoff(100*time.Millisecond, 0.5)
+		for attempt := 1; attempt < 100; attempt++ {
+			delay := backoff(attempt)
+			assert.GreaterOrEqual(t, delay, 50*time.Millisecond)
+			assert.LessOrEqual(t, delay, 150*time.Millisecond)
+		}
+	})
+
+	t.Run("jitter is clamped", func(t *testing.T) {
+		backoff := ConstantBackoff(100*time.Millisecond, 5)
+		for attempt := 1; attempt < 100; attempt++ {
+			delay := backoff(attempt)
+			assert.GreaterOrEqual(t, delay, time.Duration(0))
+			assert.LessOrEqual(t, delay, 200*time.Millisecond)
+		}
+	})
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("without jitter", func(t *testing.T) {
+		backoff := ExponentialBackoff(100*time.Millisecond, time.Second, 0)
+		assert.Equal(t, 100*time.Millisecond, backoff(1))
+		assert.Equal(t, 200*time.Millisecond, backoff(2))
+		assert.Equal(t, 400*time.Millisecond, backoff(3))
+		assert.Equal(t, 800*time.Millisecond, backoff(4))
+		assert.Equal(t, time.Second, backoff(5))
+		assert.Equal(t, time.Second, backoff(1000))
+	})
+
+	t.Run("with jitter", func(t *testing.T) {
+		backoff := ExponentialBackoff(100*time.Millisecond, time.Second, 0.1)
+		for attempt := 1; attempt < 100; attempt++ {
+			delay := backoff(attempt)
+			assert.GreaterOrEqual(t, delay, 90*time.Millisecond)
+			assert.LessOrEqual(t, delay, 1100*time.Millisecond)
+		}
+	})
+
+	t.Run("without limit", func(t *testing.T) {
+		backoff := ExponentialBackoff(time.Second, 0, 0)
+		assert.Equal(t, 8*time.Second, backoff(4))
+		for _, attempt := range []int{40, 64, 100, 1000} {
+			assert.Equal(t, time.Duration(math.MaxInt64), backoff(attempt), attempt)
+		}
+
+		jittered := ExponentialBackoff(time.Second, 0, 0.5)
+		for _, attempt := range []int{40, 64, 1000} {
+			assert.Greater(t, jittered(attempt), time.Duration(0), attempt)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package asyncqu
+
+import (
+	"sort"
+	"time"
+)
+
+// StageStatus is a snapshot of stage during or after run.
+type StageStatus struct {
+	Name        StageName
+	State       State
+	Elapsed     time.Duration // time since stage was started, duration if it is finished, zero if it was not started
+	Attempts    int
+	AttemptErrs []error // errors of each failed attempt, known when stage is finished
+	Err         error
+}
+
+// Status returns snapshot of every stage of the last run in order they were appended, END stage is the last one.
+// It is safe to call while run is in progress, before the first run every stage is Runnable.
+func (e *executorImpl) Status() []StageStatus {
+	if last := e.lastExecution(); last != nil {
+		return last.Status()
+	}
+
+	e.RLock()
+	defer e.RUnlock()
+
+	return stageStatuses(stagesWithEnd(e.stagesMap, e.stagesOrder), time.Now())
+}
+
+func stageStatuses(items []*StageMeta, now time.Time) []StageStatus {
+	statuses := make([]StageStatus, 0, len(items))
+	for _, item := range items {
+		status := StageStatus{
+			Name:        item.Name,
+			State:       item.State,
+			Attempts:    item.Attempts,
+			AttemptErrs: item.AttemptErrs,
+			Err:         item.Err,
+		}
+		switch {
+		case item.StartedAt.IsZero():
+		case item.FinishedAt.IsZero():
+			status.Elapsed = now.Sub(item.StartedAt)
+		default:
+			status.Elapsed = item.FinishedAt.Sub(item.StartedAt)
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// stageErrs returns *StageError of each failed stage ordered by completion time.
+func stageErrs(items []*StageMeta) []error {
+	failed := make([]*StageMeta, 0)
+	for _, item := range items {
+		if item.Err != nil {
+			failed = append(failed, item)
+		}
+	}
+	sort.SliceStable(failed, func(i, j int) bool {
+		return failed[i].FinishedAt.Before(failed[j].FinishedAt)
+	})
+
+	errs := make([]error, 0, len(failed))
+	for _, item := range failed {
+		errs = append(errs, &StageError{Stage: item.Name, Attempt: item.Attempts, Err: item.Err})
+	}
+
+	return errs
+}
@@ -0,0 +1,96 @@
+package asyncqu
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_executorImpl_AsyncRun(t *testing.T) {
+	t.Parallel()
+
+	var fakeErr = errors.New("fake error")
+
+	const (
+		stage1 = StageName("stage-1")
+		stage2 = StageName("stage-2")
+		stage3 = StageName("stage-3")
+	)
+	// start --> stage-1 --> stage-2 -- ERROR --> stage-3 --> end
+
+	t.Run("status while running", func(t *testing.T) {
+		release := make(chan struct{})
+
+		executor := New()
+		executor.Append(stage1, func(ctx context.Context) error {
+			return nil
+		}, Start)
+		executor.Append(stage2, func(ctx context.Context) error {
+			<-release
+			return fakeErr
+		}, stage1)
+		executor.Append(stage3, func(ctx context.Context) error {
+			return nil
+		}, stage2)
+		executor.SetEnd(stage3)
+
+		assert.NoError(t, executor.AsyncRun(context.TODO()))
+		assert.ErrorIs(t, executor.AsyncRun(context.TODO()), ErrRunInProgress)
+
+		assert.Eventually(t, func() bool {
+			return executor.Status()[1].State == Running
+		}, time.Second, time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+
+		statuses := executor.Status()
+		if assert.Len(t, statuses, 4) {
+			assert.Equal(t, StageStatus{Name: stage1, State: Done, Elapsed: statuses[0].Elapsed, Attempts: 1}, statuses[0])
+			assert.Equal(t, stage2, statuses[1].Name)
+			assert.Equal(t, Running, statuses[1].State)
+			assert.GreaterOrEqual(t, statuses[1].Elapsed, 10*time.Millisecond)
+			assert.Equal(t, StageStatus{Name: stage3, State: Runnable}, statuses[2])
+			assert.Equal(t, End, statuses[3].Name)
+		}
+
+		select {
+		case <-executor.Done():
+			t.Error("run is finished too early")
+		default:
+		}
+
+		close(release)
+
+		select {
+		case <-executor.Done():
+		case <-time.After(time.Second):
+			t.Error("run is not finished")
+		}
+
+		waitErr := executor.Wait()
+		assert.ErrorIs(t, waitErr, fakeErr)
+		assert.Same(t, executor.Report().Err, waitErr)
+
+		statuses = executor.Status()
+		assert.Equal(t, Done, statuses[1].State)
+		assert.ErrorIs(t, statuses[1].Err, fakeErr)
+		assert.Equal(t, Skipped, statuses[2].State)
+	})
+
+	t.Run("graph errors are returned immediately", func(t *testing.T) {
+		executor := New()
+		executor.SetForwardRefs(true)
+		executor.Append(stage1, nil, stage2)
+		executor.SetEnd(stage1)
+
+		assert.ErrorIs(t, executor.AsyncRun(context.TODO()), ErrStageWaitForUnknown)
+		assert.Nil(t, executor.Done())
+	})
+
+	t.Run("wait before run", func(t *testing.T) {
+		executor := New()
+		assert.ErrorIs(t, executor.Wait(), ErrNotRunYet)
+	})
+}
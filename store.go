@@ -0,0 +1,110 @@
+package asyncqu
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Store returns blackboard of the run that stage context belongs to, it is nil outside of stage.
+func Store(ctx context.Context) *Blackboard {
+	scope, ok := stageScopeFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	return scope.store
+}
+
+func NewBlackboard() *Blackboard {
+	return &Blackboard{
+		values:  map[string]any{},
+		changed: make(chan struct{}),
+	}
+}
+
+// Blackboard is a key/value store shared by all stages of one run, safe for concurrent use.
+type Blackboard struct {
+	values  map[string]any
+	changed chan struct{} // closed and replaced on each change to wake up watchers
+	mx      sync.Mutex
+}
+
+func (b *Blackboard) Get(key string) (any, bool) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	value, exists := b.values[key]
+	return value, exists
+}
+
+func (b *Blackboard) Set(key string, value any) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.values[key] = value
+	b.notify()
+}
+
+func (b *Blackboard) Delete(key string) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	delete(b.values, key)
+}
+
+// CompareAndSwap sets new value if current value of key equals old one, absent key equals nil.
+// Values of uncomparable types like slices and maps never equal, so swap of them always fails.
+func (b *Blackboard) CompareAndSwap(key string, oldValue, newValue any) bool {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	current := b.values[key]
+	if current != nil && !reflect.TypeOf(current).Comparable() {
+		return false
+	}
+	if current != oldValue {
+		return false
+	}
+
+	b.values[key] = newValue
+	b.notify()
+	return true
+}
+
+// Watch waits until key is set and returns its value.
+func (b *Blackboard) Watch(ctx context.Context, key string) (any, error) {
+	for {
+		b.mx.Lock()
+		value, exists := b.values[key]
+		changed := b.changed
+		b.mx.Unlock()
+
+		if exists {
+			return value, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Snapshot returns copy of all stored values, e.g. to save them into checkpoint.
+func (b *Blackboard) Snapshot() map[string]any {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	values := make(map[string]any, len(b.values))
+	for key, value := range b.values {
+		values[key] = value
+	}
+	return values
+}
+
+func (b *Blackboard) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
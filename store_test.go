@@ -0,0 +1,131 @@
+package asyncqu
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlackboard(t *testing.T) {
+	t.Parallel()
+
+	t.Run("get, set and delete", func(t *testing.T) {
+		board := NewBlackboard()
+
+		_, exists := board.Get("key")
+		assert.False(t, exists)
+
+		board.Set("key", 1)
+		value, exists := board.Get("key")
+		assert.True(t, exists)
+		assert.Equal(t, 1, value)
+
+		board.Delete("key")
+		_, exists = board.Get("key")
+		assert.False(t, exists)
+	})
+
+	t.Run("compare and swap", func(t *testing.T) {
+		board := NewBlackboard()
+
+		assert.True(t, board.CompareAndSwap("key", nil, 1)) // absent key equals nil
+		assert.False(t, board.CompareAndSwap("key", nil, 2))
+		assert.True(t, board.CompareAndSwap("key", 1, 2))
+
+		assert.Equal(t, map[string]any{"key": 2}, board.Snapshot())
+	})
+
+	t.Run("compare and swap of uncomparable values", func(t *testing.T) {
+		board := NewBlackboard()
+		board.Set("rows", []int{1})
+
+		assert.NotPanics(t, func() {
+			assert.False(t, board.CompareAndSwap("rows", []int{1}, []int{1, 2}))
+			assert.False(t, board.CompareAndSwap("rows", map[string]int{}, nil))
+		})
+
+		rows, _ := board.Get("rows")
+		assert.Equal(t, []int{1}, rows)
+	})
+
+	t.Run("watch", func(t *testing.T) {
+		board := NewBlackboard()
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			board.Set("other", true)
+			board.Set("key", "value")
+		}()
+
+		value, err := board.Watch(context.TODO(), "key")
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("watch canceled", func(t *testing.T) {
+		board := NewBlackboard()
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := board.Watch(ctx, "key")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	const (
+		stageCounters = 100
+		stageWatcher  = StageName("stage-watcher")
+	)
+
+	executor := New()
+
+	counters := make([]StageName, 0, stageCounters)
+	for i := 0; i < stageCounters; i++ {
+		stageName := StageName(fmt.Sprintf("stage-counter-%d", i))
+		executor.Append(stageName, func(ctx context.Context) error {
+			store := Store(ctx)
+			for {
+				current, _ := store.Get("counter")
+				next := 1
+				if current != nil {
+					next = current.(int) + 1
+				}
+				if store.CompareAndSwap("counter", current, next) {
+					break
+				}
+			}
+			return nil
+		}, Start)
+		counters = append(counters, stageName)
+	}
+
+	executor.Append(stageWatcher, func(ctx context.Context) error {
+		_, err := Store(ctx).Watch(ctx, "counter")
+		return err
+	}, Start)
+
+	var finalCounter any
+	executor.SetFinal(func(ctx context.Context) error {
+		finalCounter, _ = Store(ctx).Get("counter")
+		return nil
+	})
+
+	executor.SetEnd(append(counters, stageWatcher)...)
+
+	execErr := executor.Run(context.TODO())
+	assert.NoError(t, execErr)
+
+	assert.Len(t, executor.Errs(), 0)
+	assert.Equal(t, stageCounters, finalCounter)
+	assert.Nil(t, Store(context.TODO()))
+
+	counter, _ := executor.Store().Get("counter")
+	assert.Equal(t, stageCounters, counter)
+}
@@ -0,0 +1,192 @@
+package asyncqu
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+func newTimeline(startedAt time.Time) *timeline {
+	return &timeline{
+		startedAt: startedAt,
+		lanes:     map[StageName]int{},
+	}
+}
+
+// timeline keeps state transitions of one run with monotonic offsets from the run start.
+// Each running stage occupies a lane, lanes are reused after stages finish.
+type timeline struct {
+	startedAt time.Time
+	duration  time.Duration
+	events    []timelineEvent
+	lanes     map[StageName]int
+	busyLanes []bool
+}
+
+type timelineEvent struct {
+	stage  StageName
+	state  State
+	err    error
+	offset time.Duration
+	lane   int
+}
+
+func (t *timeline) record(stageName StageName, state State, err error, at time.Time) {
+	lane, exists := t.lanes[stageName]
+	if !exists && state == Running {
+		lane = t.takeLane()
+		t.lanes[stageName] = lane
+	}
+
+	t.events = append(t.events, timelineEvent{
+		stage:  stageName,
+		state:  state,
+		err:    err,
+		offset: at.Sub(t.startedAt),
+		lane:   lane,
+	})
+
+	if exists && isFinished(state) {
+		t.busyLanes[lane-1] = false
+		delete(t.lanes, stageName)
+	}
+}
+
+func (t *timeline) finish(at time.Time) {
+	t.duration = at.Sub(t.startedAt)
+}
+
+// takeLane returns the lowest free lane, lanes are numbered from 1, zero lane belongs to scheduler.
+func (t *timeline) takeLane() int {
+	for i, busy := range t.busyLanes {
+		if !busy {
+			t.busyLanes[i] = true
+			return i + 1
+		}
+	}
+
+	t.busyLanes = append(t.busyLanes, true)
+	return len(t.busyLanes)
+}
+
+// traceEvent is an event of Chrome trace event format, timestamps are in microseconds.
+type traceEvent struct {
+	Name string         `json:"name"`
+	Cat  string         `json:"cat,omitempty"`
+	Ph   string         `json:"ph"`
+	Ts   float64        `json:"ts"`
+	Dur  float64        `json:"dur,omitempty"`
+	Pid  int            `json:"pid"`
+	Tid  int            `json:"tid"`
+	S    string         `json:"s,omitempty"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+type traceFile struct {
+	TraceEvents     []traceEvent `json:"traceEvents"`
+	DisplayTimeUnit string       `json:"displayTimeUnit"`
+}
+
+// WriteTrace writes timeline of the last run in Chrome trace event format,
+// that can be opened with Perfetto or chrome://tracing.
+// Each attempt of stage is a slice on its lane, backoff delays and skipped stages are shown too.
+func (e *executorImpl) WriteTrace(w io.Writer) error {
+	if last := e.lastExecution(); last != nil {
+		return last.WriteTrace(w)
+	}
+
+	return writeTrace(w, newTimeline(time.Time{}).traceEvents())
+}
+
+func writeTrace(w io.Writer, trace []traceEvent) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(traceFile{TraceEvents: trace, DisplayTimeUnit: "ms"})
+}
+
+func (t *timeline) traceEvents() []traceEvent {
+	const pid = 1
+
+	micros := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Microsecond)
+	}
+
+	trace := []traceEvent{
+		{Name: "process_name", Ph: "M", Pid: pid, Args: map[string]any{"name": "asyncqu"}},
+		{Name: "thread_name", Ph: "M", Pid: pid, Tid: 0, Args: map[string]any{"name": "scheduler"}},
+	}
+
+	lanes := 0
+	for _, ev := range t.events {
+		if ev.lane > lanes {
+			lanes = ev.lane
+		}
+	}
+	for lane := 1; lane <= lanes; lane++ {
+		trace = append(trace, traceEvent{
+			Name: "thread_name", Ph: "M", Pid: pid, Tid: lane,
+			Args: map[string]any{"name": "lane " + strconv.Itoa(lane)},
+		})
+	}
+
+	if t.duration > 0 {
+		trace = append(trace, traceEvent{Name: "run", Cat: "run", Ph: "X", Ts: 0, Dur: micros(t.duration), Pid: pid, Tid: 0})
+	}
+
+	type openSlice struct {
+		name    string
+		cat     string
+		offset  time.Duration
+		attempt int
+	}
+
+	var (
+		open     = map[StageName]openSlice{}
+		attempts = map[StageName]int{}
+	)
+
+	closeSlice := func(ev timelineEvent) {
+		slice, exists := open[ev.stage]
+		if !exists {
+			return
+		}
+		delete(open, ev.stage)
+
+		args := map[string]any{"stage": string(ev.stage), "attempt": slice.attempt}
+		if slice.cat == "stage" {
+			args["state"] = string(ev.state)
+		}
+		if ev.err != nil && slice.cat == "stage" {
+			args["error"] = ev.err.Error()
+		}
+
+		trace = append(trace, traceEvent{
+			Name: slice.name, Cat: slice.cat, Ph: "X",
+			Ts: micros(slice.offset), Dur: micros(ev.offset - slice.offset),
+			Pid: pid, Tid: ev.lane, Args: args,
+		})
+	}
+
+	for _, ev := range t.events {
+		switch ev.state {
+		case Running:
+			closeSlice(ev) // backoff
+			attempts[ev.stage]++
+			open[ev.stage] = openSlice{name: string(ev.stage), cat: "stage", offset: ev.offset, attempt: attempts[ev.stage]}
+		case Retrying:
+			closeSlice(ev)
+			open[ev.stage] = openSlice{name: string(ev.stage) + " backoff", cat: "backoff", offset: ev.offset, attempt: attempts[ev.stage]}
+		case Skipped:
+			trace = append(trace, traceEvent{
+				Name: string(ev.stage), Cat: "skipped", Ph: "i", Ts: micros(ev.offset), Pid: pid, Tid: 0, S: "t",
+				Args: map[string]any{"stage": string(ev.stage)},
+			})
+		default:
+			closeSlice(ev)
+		}
+	}
+
+	return trace
+}
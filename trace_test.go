@@ -0,0 +1,88 @@
+package asyncqu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_executorImpl_WriteTrace(t *testing.T) {
+	t.Parallel()
+
+	var fakeErr = errors.New("fake error")
+
+	const (
+		stage1  = StageName("stage-1")
+		stage21 = StageName("stage-2-1")
+		stage22 = StageName("stage-2-2")
+		stage3  = StageName("stage-3")
+	)
+	// start --> stage-1 --> stage-2-1 ---------> end
+	//                  \--> stage-2-2 -- ERROR --> stage-3 --/
+
+	fnSleep := func(ctx context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}
+
+	executor := New()
+	executor.SetFailurePolicy(ContinueIndependent)
+	executor.Append(stage1, fnSleep, Start)
+	executor.Append(stage21, fnSleep, stage1)
+	executor.Append(stage22, func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return fakeErr
+	}, stage1)
+	executor.SetStageRetry(stage22, RetryPolicy{MaxAttempts: 2, Backoff: ConstantBackoff(5*time.Millisecond, 0)})
+	executor.Append(stage3, fnSleep, stage22)
+	executor.SetEnd(stage21, stage3)
+
+	_ = executor.Run(context.TODO())
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, executor.WriteTrace(&buf))
+
+	var trace struct {
+		TraceEvents []traceEvent `json:"traceEvents"`
+	}
+	if !assert.NoError(t, json.Unmarshal(buf.Bytes(), &trace)) {
+		return
+	}
+
+	slices := map[string][]traceEvent{}
+	for _, ev := range trace.TraceEvents {
+		if ev.Ph == "X" || ev.Ph == "i" {
+			slices[ev.Name] = append(slices[ev.Name], ev)
+		}
+	}
+
+	assert.Len(t, slices["run"], 1)
+	assert.Len(t, slices[string(stage1)], 1)
+	assert.Len(t, slices[string(stage21)], 1)
+	assert.Len(t, slices[string(stage22)], 2) // two attempts
+	assert.Len(t, slices[string(stage22)+" backoff"], 1)
+
+	s1 := slices[string(stage1)][0]
+	assert.GreaterOrEqual(t, s1.Dur, float64(20*time.Millisecond/time.Microsecond))
+
+	// stage-2-1 and stage-2-2 run in parallel on different lanes
+	s21 := slices[string(stage21)][0]
+	s22 := slices[string(stage22)][0]
+	assert.NotEqual(t, s21.Tid, s22.Tid)
+	assert.GreaterOrEqual(t, s21.Ts, s1.Ts+s1.Dur)
+	assert.Less(t, s22.Ts, s21.Ts+s21.Dur)
+
+	lastAttempt := slices[string(stage22)][1]
+	assert.Equal(t, float64(2), lastAttempt.Args["attempt"])
+	assert.Equal(t, "fake error", lastAttempt.Args["error"])
+
+	if assert.Len(t, slices[string(stage3)], 1) {
+		assert.Equal(t, "i", slices[string(stage3)][0].Ph)
+		assert.Equal(t, "skipped", slices[string(stage3)][0].Cat)
+	}
+}
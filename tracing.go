@@ -0,0 +1,58 @@
+package asyncqu
+
+import "context"
+
+// Tracer starts spans of run, stages and final callback.
+// It keeps the package free of tracing dependencies, adapters implement it for particular tracing systems.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttributes(attrs ...Attr)
+	AddEvent(name string, attrs ...Attr)
+	RecordError(err error)
+	End()
+}
+
+// Attr is a span attribute, value is one of string, bool, int, int64, float64 or []string.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+const (
+	SpanNameRun   = "asyncqu.run"
+	SpanNameFinal = "asyncqu.final"
+
+	AttrStageName   = "asyncqu.stage.name"
+	AttrStageCauses = "asyncqu.stage.causes"
+	AttrStageState  = "asyncqu.stage.state"
+	AttrAttempt     = "asyncqu.stage.attempt"
+	AttrSkipReason  = "asyncqu.stage.skip_reason"
+	AttrStagesCount = "asyncqu.stages.count"
+
+	EventStageSkipped = "stage skipped"
+	EventStageRetry   = "stage retry"
+)
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...Attr) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attr)    {}
+func (noopSpan) AddEvent(string, ...Attr) {}
+func (noopSpan) RecordError(error)        {}
+func (noopSpan) End()                     {}
+
+func causesAttr(causes []StageName) Attr {
+	names := make([]string, 0, len(causes))
+	for _, c := range causes {
+		names = append(names, string(c))
+	}
+	return Attr{Key: AttrStageCauses, Value: names}
+}
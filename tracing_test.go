@@ -0,0 +1,176 @@
+package asyncqu
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSpanKey struct{}
+
+type fakeSpan struct {
+	name   string
+	parent *fakeSpan
+	attrs  map[string]any
+	events []string
+	errs   []error
+	ended  bool
+}
+
+type fakeTracer struct {
+	sync.Mutex
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span) {
+	t.Lock()
+	defer t.Unlock()
+
+	parent, _ := ctx.Value(fakeSpanKey{}).(*fakeSpan)
+	span := &fakeSpan{name: name, parent: parent, attrs: map[string]any{}}
+	for _, a := range attrs {
+		span.attrs[a.Key] = a.Value
+	}
+	t.spans = append(t.spans, span)
+
+	return context.WithValue(ctx, fakeSpanKey{}, span), &fakeSpanHandle{tracer: t, span: span}
+}
+
+func (t *fakeTracer) byName(name string) *fakeSpan {
+	t.Lock()
+	defer t.Unlock()
+
+	for _, s := range t.spans {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+type fakeSpanHandle struct {
+	tracer *fakeTracer
+	span   *fakeSpan
+}
+
+func (h *fakeSpanHandle) SetAttributes(attrs ...Attr) {
+	h.tracer.Lock()
+	defer h.tracer.Unlock()
+
+	for _, a := range attrs {
+		h.span.attrs[a.Key] = a.Value
+	}
+}
+
+func (h *fakeSpanHandle) AddEvent(name string, attrs ...Attr) {
+	h.tracer.Lock()
+	defer h.tracer.Unlock()
+
+	for _, a := range attrs {
+		if a.Key == AttrStageName {
+			name += ":" + a.Value.(string)
+		}
+	}
+	h.span.events = append(h.span.events, name)
+}
+
+func (h *fakeSpanHandle) RecordError(err error) {
+	h.tracer.Lock()
+	defer h.tracer.Unlock()
+
+	h.span.errs = append(h.span.errs, err)
+}
+
+func (h *fakeSpanHandle) End() {
+	h.tracer.Lock()
+	defer h.tracer.Unlock()
+
+	h.span.ended = true
+}
+
+func Test_executorImpl_SetTracer(t *testing.T) {
+	t.Parallel()
+
+	var fakeErr = errors.New("fake error")
+
+	const (
+		stage1 = StageName("stage-1")
+		stage2 = StageName("stage-2")
+		stage3 = StageName("stage-3")
+	)
+	// start --> stage-1 --> stage-2 -- ERROR --> stage-3 --> end
+
+	tracer := &fakeTracer{}
+
+	executor := New()
+	executor.SetTracer(tracer)
+	executor.Append(stage1, func(ctx context.Context) error {
+		_, span := tracer.Start(ctx, "user-span")
+		span.End()
+		return nil
+	}, Start)
+	executor.Append(stage2, func(ctx context.Context) error {
+		return fakeErr
+	}, stage1)
+	executor.SetStageRetry(stage2, RetryPolicy{MaxAttempts: 2, Backoff: ConstantBackoff(time.Millisecond, 0)})
+	executor.Append(stage3, func(ctx context.Context) error {
+		return nil
+	}, stage2)
+	executor.SetEnd(stage3)
+	executor.SetFinal(func(ctx context.Context) error {
+		return nil
+	})
+
+	runErr := executor.Run(context.TODO())
+	assert.ErrorIs(t, runErr, fakeErr)
+
+	t.Run("run span is parent of stages", func(t *testing.T) {
+		run := tracer.byName(SpanNameRun)
+		if !assert.NotNil(t, run) {
+			return
+		}
+		assert.True(t, run.ended)
+		assert.Equal(t, 4, run.attrs[AttrStagesCount]) // End is counted too
+		assert.Len(t, run.errs, 1)
+
+		for _, name := range []string{string(stage1), string(stage2), SpanNameFinal} {
+			span := tracer.byName(name)
+			if assert.NotNil(t, span, name) {
+				assert.Same(t, run, span.parent, name)
+				assert.True(t, span.ended, name)
+			}
+		}
+		assert.Nil(t, tracer.byName(string(stage3)))
+	})
+
+	t.Run("stage attributes", func(t *testing.T) {
+		span := tracer.byName(string(stage2))
+		if !assert.NotNil(t, span) {
+			return
+		}
+		assert.Equal(t, string(stage2), span.attrs[AttrStageName])
+		assert.Equal(t, []string{string(stage1)}, span.attrs[AttrStageCauses])
+		assert.Equal(t, 2, span.attrs[AttrAttempt])
+		assert.Equal(t, string(Done), span.attrs[AttrStageState])
+		assert.Equal(t, []string{EventStageRetry}, span.events)
+		assert.Len(t, span.errs, 2)
+	})
+
+	t.Run("stage context carries span", func(t *testing.T) {
+		span := tracer.byName("user-span")
+		if assert.NotNil(t, span) {
+			assert.Same(t, tracer.byName(string(stage1)), span.parent)
+		}
+	})
+
+	t.Run("skipped stage is run event", func(t *testing.T) {
+		run := tracer.byName(SpanNameRun)
+		if assert.NotNil(t, run) {
+			assert.Contains(t, run.events, EventStageSkipped+":"+string(stage3))
+		}
+	})
+}
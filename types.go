@@ -1,5 +1,7 @@
 package asyncqu
 
+import "time"
+
 type StageName string
 
 const (
@@ -11,16 +13,55 @@ const (
 type State string
 
 const (
-	Runnable = State("runnable")
-	Running  = State("running")
-	Done     = State("done")
-	Skipped  = State("skipped")
+	Runnable    = State("runnable")
+	Running     = State("running")
+	Done        = State("done")
+	Skipped     = State("skipped")
+	Interrupted = State("interrupted") // was running when FailFast policy cancelled it, returned context.Canceled
+	Retrying    = State("retrying")    // attempt failed, waiting for the next one
+	Cancelled   = State("cancelled")   // was running when context of Run was done, returned an error or was abandoned
 )
 
 type StageMeta struct {
-	Name   StageName
-	Fn     StageFn
-	State  State
-	Causes []StageName
-	Err    error
+	Name    StageName
+	Fn      StageFn
+	State   State
+	Causes  []StageName
+	Err     error
+	Timeout time.Duration // overrides executor-wide timeout if positive
+	Leaked  bool          // stage function ignored timeout and still was running when executor gave up on it
+
+	Retry       RetryPolicy
+	Attempts    int
+	AttemptErrs []error // errors of each failed attempt
+
+	Cost Resources // units of each pool stage holds while running
+
+	ReadyAt    time.Time // all causes are done, stage may wait for free slot after it
+	StartedAt  time.Time
+	FinishedAt time.Time
+	SkippedBy  StageName // cause that failed or was skipped, or failed stage that stopped scheduling
+	SkipReason string
+}
+
+type FailurePolicy string
+
+const (
+	// StopScheduling stops starting new stages after the first failure,
+	// already running stages are allowed to finish.
+	StopScheduling = FailurePolicy("stop-scheduling")
+	// ContinueIndependent skips only stages that depend on failed stage,
+	// independent branches keep running.
+	ContinueIndependent = FailurePolicy("continue-independent")
+	// FailFast stops starting new stages and cancels context of running stages after the first failure.
+	FailFast = FailurePolicy("fail-fast")
+)
+
+// Resources maps pool name to count of units.
+type Resources map[string]int
+
+type PoolStatus struct {
+	Name     string
+	Capacity int
+	InUse    int
 }
@@ -0,0 +1,237 @@
+package asyncqu
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Validate checks whole graph and returns all found problems joined,
+// each problem is *ValidationError that identifies the stage.
+func (e *executorImpl) Validate() error {
+	e.RLock()
+	defer e.RUnlock()
+
+	problems := make([]error, 0, len(e.appendErrs))
+	problems = append(problems, e.appendErrs...)
+
+	_, hasEnd := e.stagesMap[End]
+	if !hasEnd {
+		problems = append(problems, &ValidationError{Stage: End, Err: ErrEndStageIsNotSpecified})
+	}
+
+	items := make([]*StageMeta, 0, len(e.stagesMap))
+	for _, stageName := range e.stagesOrder {
+		items = append(items, e.stagesMap[stageName])
+	}
+
+	problems = append(problems, e.unresolvedProblems()...)
+	problems = append(problems, e.costProblems()...)
+
+	reachable := e.reachableFromStart()
+	for _, item := range items {
+		if !reachable[item.Name] {
+			problems = append(problems, &ValidationError{Stage: item.Name, Err: ErrStageUnreachable})
+		}
+	}
+
+	if hasEnd {
+		leadsToEnd := e.leadingTo(End)
+		for _, item := range items {
+			if !leadsToEnd[item.Name] {
+				problems = append(problems, &ValidationError{Stage: item.Name, Err: ErrStageDeadEnd})
+			}
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// checkResolved returns problems that make graph impossible to run: unknown causes and cycles.
+func (e *executorImpl) checkResolved() error {
+	e.RLock()
+	defer e.RUnlock()
+
+	return errors.Join(e.unresolvedProblems()...)
+}
+
+func (e *executorImpl) unresolvedProblems() []error {
+	var problems []error
+
+	items := stagesWithEnd(e.stagesMap, e.stagesOrder)
+	for _, item := range items {
+		for _, c := range item.Causes {
+			if _, exists := e.stagesMap[c]; !exists && c != Start {
+				problems = append(problems, &ValidationError{Stage: item.Name, Cause: c, Err: ErrStageWaitForUnknown})
+			}
+		}
+	}
+
+	return append(problems, e.findCycles(items)...)
+}
+
+// costProblems reports stage costs that cannot be satisfied with pools.
+func (e *executorImpl) costProblems() []error {
+	var problems []error
+
+	for _, item := range stagesWithEnd(e.stagesMap, e.stagesOrder) {
+		poolNames := make([]string, 0, len(item.Cost))
+		for poolName := range item.Cost {
+			poolNames = append(poolNames, poolName)
+		}
+		sort.Strings(poolNames)
+
+		for _, poolName := range poolNames {
+			units := item.Cost[poolName]
+			p, exists := e.pools[poolName]
+			if !exists {
+				problems = append(problems, &ValidationError{
+					Stage: item.Name,
+					Err:   fmt.Errorf("requires pool '%s': %w", poolName, ErrPoolUnknown),
+				})
+				continue
+			}
+			if units > p.Capacity {
+				problems = append(problems, &ValidationError{
+					Stage: item.Name,
+					Err:   fmt.Errorf("requires %d units of pool '%s': %w", units, poolName, ErrPoolCapacityExceeded),
+				})
+			}
+		}
+	}
+
+	return problems
+}
+
+// findCycles walks through causes of each stage and reports cycles in execution order, e.g. a -> b -> c -> a.
+func (e *executorImpl) findCycles(items []*StageMeta) []error {
+	const (
+		unvisited = iota
+		inStack
+		visited
+	)
+
+	var (
+		problems []error
+		marks    = map[StageName]int{}
+		stack    []StageName
+		visit    func(stageName StageName)
+	)
+
+	visit = func(stageName StageName) {
+		item, exists := e.stagesMap[stageName]
+		if !exists {
+			return
+		}
+
+		marks[stageName] = inStack
+		stack = append(stack, stageName)
+
+		for _, c := range item.Causes {
+			switch marks[c] {
+			case unvisited:
+				visit(c)
+			case inStack:
+				problems = append(problems, &ValidationError{Stage: c, Err: fmt.Errorf("%w: %s", ErrStageCycle, cyclePath(stack, c))})
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		marks[stageName] = visited
+	}
+
+	for _, item := range items {
+		if marks[item.Name] == unvisited {
+			visit(item.Name)
+		}
+	}
+
+	return problems
+}
+
+// cyclePath formats cycle found in stack of stages waiting for each other in order of execution.
+func cyclePath(stack []StageName, target StageName) string {
+	start := 0
+	for i, stageName := range stack {
+		if stageName == target {
+			start = i
+		}
+	}
+
+	path := []string{string(target)}
+	for i := len(stack) - 1; i > start; i-- {
+		path = append(path, string(stack[i]))
+	}
+	path = append(path, string(target))
+
+	return strings.Join(path, " -> ")
+}
+
+// stagesWithEnd returns stages in order they were appended, END stage is the last one if it exists.
+func stagesWithEnd(stagesMap map[StageName]*StageMeta, stagesOrder []StageName) []*StageMeta {
+	items := make([]*StageMeta, 0, len(stagesMap))
+	for _, stageName := range stagesOrder {
+		items = append(items, stagesMap[stageName])
+	}
+	if item, exists := stagesMap[End]; exists {
+		items = append(items, item)
+	}
+	return items
+}
+
+// reachableFromStart returns stages that are started after START directly or through other stages.
+// Stage without causes is started right after START. Unknown causes are ignored, they are reported separately.
+func (e *executorImpl) reachableFromStart() map[StageName]bool {
+	reachable := map[StageName]bool{Start: true}
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, item := range e.stagesMap {
+			if reachable[item.Name] {
+				continue
+			}
+
+			known := 0
+			allReachable := true
+			for _, c := range item.Causes {
+				if _, exists := e.stagesMap[c]; !exists && c != Start {
+					continue
+				}
+				known++
+				allReachable = allReachable && reachable[c]
+			}
+
+			if (len(item.Causes) == 0 || known > 0) && allReachable {
+				reachable[item.Name] = true
+				changed = true
+			}
+		}
+	}
+
+	return reachable
+}
+
+// leadingTo returns stages that target stage waits for directly or through other stages.
+func (e *executorImpl) leadingTo(target StageName) map[StageName]bool {
+	leading := map[StageName]bool{}
+
+	queue := []StageName{target}
+	for len(queue) > 0 {
+		item, exists := e.stagesMap[queue[0]]
+		queue = queue[1:]
+		if !exists {
+			continue
+		}
+
+		for _, c := range item.Causes {
+			if !leading[c] {
+				leading[c] = true
+				queue = append(queue, c)
+			}
+		}
+	}
+
+	return leading
+}
@@ -0,0 +1,174 @@
+package asyncqu
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_executorImpl_Validate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("positive", func(t *testing.T) {
+		executor := New()
+		assert.NoError(t, executor.TryAppend("stage-1", nil, Start))
+		assert.NoError(t, executor.TryAppend("stage-2-1", nil, "stage-1"))
+		assert.NoError(t, executor.TryAppend("stage-2-2", nil, "stage-1"))
+		executor.SetEnd("stage-2-1", "stage-2-2")
+
+		assert.NoError(t, executor.Validate())
+	})
+
+	t.Run("positive - START == END", func(t *testing.T) {
+		executor := New()
+		assert.NoError(t, executor.Validate())
+	})
+
+	t.Run("negative - collects all problems", func(t *testing.T) {
+		executor := New()
+		assert.NoError(t, executor.TryAppend("stage-1", nil, Start))
+		assert.ErrorIs(t, executor.TryAppend("stage-1", nil, Start), ErrStageDuplicate)
+		assert.ErrorIs(t, executor.TryAppend("stage-2", nil, "stage-2"), ErrStageShouldNotWaitForItself)
+		assert.ErrorIs(t, executor.TryAppend("stage-3", nil, "stage-unknown"), ErrStageWaitForUnknown)
+		assert.NoError(t, executor.TryAppend("stage-orphan", nil))
+		assert.NoError(t, executor.TryAppend("stage-dead-end", nil, "stage-1"))
+		executor.SetEnd("stage-1", "stage-missing")
+
+		validateErr := executor.Validate()
+		assert.ErrorIs(t, validateErr, ErrStageDuplicate)
+		assert.ErrorIs(t, validateErr, ErrStageShouldNotWaitForItself)
+		assert.ErrorIs(t, validateErr, ErrStageWaitForUnknown)
+		assert.ErrorIs(t, validateErr, ErrStageDeadEnd)
+
+		var problems []string
+		for _, err := range validateErr.(interface{ Unwrap() []error }).Unwrap() {
+			var validationErr *ValidationError
+			if assert.True(t, errors.As(err, &validationErr)) {
+				problems = append(problems, validationErr.Error())
+			}
+		}
+		assert.Equal(t, []string{
+			"stage 'stage-1': stage already exists",
+			"stage 'stage-2': stage should not wait for itself",
+			"stage 'stage-3': stage wait for unknown 'stage-unknown'",
+			"stage 'end': stage wait for unknown 'stage-missing'",
+			"stage 'stage-orphan': stage does not lead to end",
+			"stage 'stage-dead-end': stage does not lead to end",
+		}, problems)
+	})
+
+	t.Run("positive - stage without causes", func(t *testing.T) {
+		var visited bool
+
+		executor := New()
+		executor.Append("stage-1", func(ctx context.Context) error {
+			visited = true
+			return nil
+		})
+		executor.SetEnd("stage-1")
+
+		assert.NoError(t, executor.Validate())
+		assert.NoError(t, executor.Run(context.TODO()))
+		assert.True(t, visited)
+	})
+
+	t.Run("negative - unreachable stages", func(t *testing.T) {
+		executor := New()
+		executor.SetForwardRefs(true)
+		executor.Append("stage-1", nil, Start)
+		executor.Append("stage-2", nil, "stage-3")
+		executor.Append("stage-3", nil, "stage-2")
+		executor.SetEnd("stage-1", "stage-3")
+
+		validateErr := executor.Validate()
+		assert.ErrorIs(t, validateErr, ErrStageCycle)
+		assert.ErrorIs(t, validateErr, ErrStageUnreachable)
+		assert.Contains(t, validateErr.Error(), "stage 'stage-2': stage is unreachable from start")
+		assert.Contains(t, validateErr.Error(), "stage 'stage-3': stage is unreachable from start")
+	})
+
+	t.Run("negative - pools", func(t *testing.T) {
+		executor := New()
+		executor.SetPool("db", 1)
+		executor.Append("stage-1", nil, Start)
+		executor.SetStageCost("stage-1", Resources{"db": 2, "gpu": 1})
+		executor.SetEnd("stage-1")
+
+		validateErr := executor.Validate()
+		assert.ErrorIs(t, validateErr, ErrPoolCapacityExceeded)
+		assert.ErrorIs(t, validateErr, ErrPoolUnknown)
+		assert.EqualError(t, validateErr, "stage 'stage-1': requires 2 units of pool 'db': pool capacity exceeded\n"+
+			"stage 'stage-1': requires pool 'gpu': pool is unknown")
+
+		execErr := executor.Run(context.TODO())
+		assert.EqualError(t, execErr, validateErr.Error())
+	})
+
+	t.Run("negative - no END stage", func(t *testing.T) {
+		executor := New()
+		assert.NoError(t, executor.TryAppend("stage-1", nil, Start))
+
+		assert.ErrorIs(t, executor.Validate(), ErrEndStageIsNotSpecified)
+
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrEndStageIsNotSpecified)
+	})
+}
+
+func Test_executorImpl_SetForwardRefs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("positive - stages in any order", func(t *testing.T) {
+		spy := NewStageVisitSpy()
+		fnSpy := func(ctx context.Context) error {
+			spy.Append(StageNameFromContext(ctx))
+			return nil
+		}
+
+		executor := New()
+		executor.SetForwardRefs(true)
+		executor.Append("stage-3", fnSpy, "stage-2")
+		executor.Append("stage-2", fnSpy, "stage-1")
+		executor.Append("stage-1", fnSpy, Start)
+		executor.SetEnd("stage-3")
+
+		assert.NoError(t, executor.Validate())
+
+		execErr := executor.Run(context.TODO())
+		assert.NoError(t, execErr)
+
+		assert.Equal(t, 3, spy.Len())
+		assert.Equal(t, StageName("stage-1"), spy.At(0))
+		assert.Equal(t, StageName("stage-2"), spy.At(1))
+		assert.Equal(t, StageName("stage-3"), spy.At(2))
+	})
+
+	t.Run("negative - cycle", func(t *testing.T) {
+		executor := New()
+		executor.SetForwardRefs(true)
+		executor.Append("a", nil, Start, "c")
+		executor.Append("b", nil, "a")
+		executor.Append("c", nil, "b")
+		executor.SetEnd("c")
+
+		validateErr := executor.Validate()
+		assert.ErrorIs(t, validateErr, ErrStageCycle)
+		assert.Contains(t, validateErr.Error(), "stage 'a': dependency cycle: a -> b -> c -> a")
+
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrStageCycle)
+	})
+
+	t.Run("negative - unknown cause is reported by Run", func(t *testing.T) {
+		executor := New()
+		executor.SetForwardRefs(true)
+		executor.Append("stage-1", nil, Start, "stage-unknown")
+		executor.SetEnd("stage-1")
+
+		execErr := executor.Run(context.TODO())
+		assert.ErrorIs(t, execErr, ErrStageWaitForUnknown)
+		assert.EqualError(t, execErr, "stage 'stage-1': stage wait for unknown 'stage-unknown'")
+	})
+}